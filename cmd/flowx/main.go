@@ -10,17 +10,22 @@ import (
 
 	// Local Packages
 	config "flowx/config"
+	flow "flowx/flow"
 	http "flowx/http"
 	handlers "flowx/http/handlers"
 	mongodb "flowx/repositories/mongodb"
+	catalog "flowx/services/catalog"
+	events "flowx/services/events"
 	executor "flowx/services/executor"
 	health "flowx/services/health"
 	runsvc "flowx/services/run"
+	schedule "flowx/services/schedule"
 	helpers "flowx/utils/helpers"
 	slack "flowx/utils/slack"
 
 	// External Packages
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/google/uuid"
 	_ "github.com/jsternberg/zap-logfmt"
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
@@ -44,11 +49,37 @@ func InitializeServer(ctx context.Context, k config.Config, logger *zap.Logger)
 	// Repositories
 	runRepo := mongodb.NewRunRepository(mongoClient)
 	stepRunRepo := mongodb.NewStepRunRepository(mongoClient)
+	scheduleRepo := mongodb.NewScheduleRepository(mongoClient)
+	if err = scheduleRepo.CreateIndexes(ctx); err != nil {
+		logger.Error("Failed To Create Schedule Indexes", zap.Error(err))
+		return nil, err
+	}
+	if err = runRepo.CreateIndexes(ctx); err != nil {
+		logger.Error("Failed To Create Run Indexes", zap.Error(err))
+		return nil, err
+	}
+
+	// Run Queue
+	var queue runsvc.Queue = runsvc.NewChannelQueue(k.Queue.Size)
+	if k.Queue.Type == config.QueueMongo {
+		runQueue := mongodb.NewRunQueue(mongoClient, helpers.Seconds(k.Queue.VisibilityTimeout), helpers.Seconds(k.Queue.PollInterval))
+		if err = runQueue.CreateIndexes(ctx); err != nil {
+			logger.Error("Failed To Create Run Queue Indexes", zap.Error(err))
+			return nil, err
+		}
+		queue = runQueue
+	}
 
 	// Services
 	healthSVC := health.NewService(logger, mongoClient)
-	executorSVC := executor.NewService(logger, k.Executor, stepRunRepo)
-	runSvc := runsvc.NewService(logger, k.Queue, runRepo, executorSVC, slackAlerter)
+	catalogSVC := catalog.NewService(k.Executor)
+	eventBus := events.NewBus(logger)
+	executorSVC := executor.NewService(logger, k.Executor, stepRunRepo, eventBus)
+	instanceID := InstanceID(k)
+	logger.Info("Starting Instance", zap.String("instanceId", instanceID))
+	runSvc := runsvc.NewService(logger, k.Queue, k.Lease, k.Scheduler, instanceID, queue, runRepo, stepRunRepo, executorSVC, eventBus, slackAlerter)
+
+	scheduleSvc := schedule.NewService(logger, k.Scheduler, scheduleRepo, runSvc)
 
 	// Start the run service (spawns workers and re-enqueues incomplete runs)
 	if err = runSvc.Start(ctx); err != nil {
@@ -56,19 +87,50 @@ func InitializeServer(ctx context.Context, k config.Config, logger *zap.Logger)
 		return nil, err
 	}
 
+	// Sync the schedules defined in the config file and start firing them
+	if err = scheduleSvc.Sync(ctx, k.Schedules); err != nil {
+		logger.Error("Failed To Sync Schedules", zap.Error(err))
+		return nil, err
+	}
+	scheduleSvc.Start(ctx)
+
 	// Handlers
 	healthHandler := handlers.NewHealthCheckHandler(healthSVC)
 	runHandler := handlers.NewRunHandler(runSvc)
+	flowHandler := handlers.NewFlowHandler(catalogSVC)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleSvc)
 
+	// Drain the run service once the HTTP server no longer accepts requests
+	// and schedules no longer fire, and disconnect from MongoDB only after
+	// every worker has stopped.
 	closeCallback := func() {
-		_ = mongoClient.Disconnect(ctx)
+		scheduleSvc.Stop()
+
+		drainCtx, cancel := context.WithTimeout(context.Background(), helpers.Seconds(k.Shutdown.DrainTimeout))
+		defer cancel()
+		if err := runSvc.Shutdown(drainCtx); err != nil {
+			logger.Warn("Runs Interrupted After Drain Timeout", zap.Error(err))
+		}
+
+		_ = mongoClient.Disconnect(context.Background())
 		logger.Info("Server Stopped Successfully")
 	}
 
-	server := http.NewServer(logger, k.Prefix, healthHandler, runHandler, closeCallback)
+	server := http.NewServer(logger, k.Prefix, healthHandler, runHandler, flowHandler, scheduleHandler, closeCallback)
 	return server, nil
 }
 
+// InstanceID returns the configured instance ID, or one derived from the
+// hostname and a random suffix so that restarted instances never reuse the
+// leases of their previous incarnation.
+func InstanceID(k config.Config) string {
+	if k.InstanceID != "" {
+		return k.InstanceID
+	}
+	hostname, _ := os.Hostname()
+	return hostname + "-" + uuid.New().String()[:8]
+}
+
 // LoadConfig loads the default configuration and overrides it with the config file
 // specified by the --config flag.
 func LoadConfig() *koanf.Koanf {
@@ -115,12 +177,24 @@ func main() {
 		log.Fatalf("Error Loading Config: %v", err)
 	}
 
+	// Load Declarative Flows (before validation, as executor.flow may name one)
+	if err := flow.LoadDir(appKonf.Flows.Dir); err != nil {
+		helpers.LogValidationErrors(err)
+		log.Fatalf("Error Loading Flow Definitions: %v", err)
+	}
+
 	// Validate Config
 	if err := appKonf.Validate(); err != nil {
 		helpers.LogValidationErrors(err)
 		log.Fatalf("Invalid Configuration")
 	}
 
+	// Validate Flow Definitions
+	if err := flow.ValidateRegistry(); err != nil {
+		helpers.LogValidationErrors(err)
+		log.Fatalf("Invalid Flow Definitions")
+	}
+
 	// Print Config in Dev Mode
 	if !appKonf.IsProdMode {
 		k.Print()
@@ -7,6 +7,7 @@ import (
 	// Local Packages
 	errors "flowx/errors"
 	flow "flowx/flow"
+	schedule "flowx/models/schedule"
 	helpers "flowx/utils/helpers"
 )
 
@@ -23,12 +24,32 @@ prefix: "/flowx"
 
 is_prod_mode: false
 
+instance_id: ""
+
 mongo:
   uri: "mongodb://localhost:27017"
 
 queue:
+  type: "mongo"
   size: 50
   workers: 5
+  visibility_timeout: 60
+  poll_interval: 1
+
+lease:
+  duration: 30
+  sweep_interval: 10
+
+scheduler:
+  poll_interval: 1
+
+schedules: []
+
+shutdown:
+  drain_timeout: 30
+
+flows:
+  dir: ""
 
 executor:
   flow: "default"
@@ -37,6 +58,8 @@ executor:
   max_backoff: 300
   backoff_factor: 2.0
   jitter_fraction: 0.2
+  max_elapsed_time: 0
+  step_timeout: 0
 
 slack:
   webhook_url: "https://hooks.slack.com/services/your/webhook/url"
@@ -44,15 +67,21 @@ slack:
 `)
 
 type Config struct {
-	Application string   `koanf:"application"`
-	Logger      Logger   `koanf:"logger"`
-	Listen      string   `koanf:"listen"`
-	Prefix      string   `koanf:"prefix"`
-	IsProdMode  bool     `koanf:"is_prod_mode"`
-	Mongo       Mongo    `koanf:"mongo"`
-	Queue       Queue    `koanf:"queue"`
-	Executor    Executor `koanf:"executor"`
-	Slack       Slack    `koanf:"slack"`
+	Application string                `koanf:"application"`
+	Logger      Logger                `koanf:"logger"`
+	Listen      string                `koanf:"listen"`
+	Prefix      string                `koanf:"prefix"`
+	IsProdMode  bool                  `koanf:"is_prod_mode"`
+	InstanceID  string                `koanf:"instance_id"` // Empty derives one from the hostname
+	Mongo       Mongo                 `koanf:"mongo"`
+	Queue       Queue                 `koanf:"queue"`
+	Lease       Lease                 `koanf:"lease"`
+	Scheduler   Scheduler             `koanf:"scheduler"`
+	Schedules   []schedule.Definition `koanf:"schedules"`
+	Shutdown    Shutdown              `koanf:"shutdown"`
+	Flows       Flows                 `koanf:"flows"`
+	Executor    Executor              `koanf:"executor"`
+	Slack       Slack                 `koanf:"slack"`
 }
 
 type Logger struct {
@@ -64,20 +93,81 @@ type Mongo struct {
 	URI string `koanf:"uri"`
 }
 
+// Queue types.
+const (
+	QueueMongo  = "mongo"  // Durable, shared by every instance
+	QueueMemory = "memory" // In-process channel of Size runs
+)
+
+// Queue is the configuration for the run queue and the workers consuming it.
+// A run claimed by a worker stays hidden from the others for
+// VisibilityTimeout, which the worker keeps extending while it processes the
+// run. Idle workers check the mongo queue for runs every PollInterval.
+// Durations are in seconds.
 type Queue struct {
-	Size    int `koanf:"size"`
-	Workers int `koanf:"workers"`
+	Type              string  `koanf:"type"`
+	Size              int     `koanf:"size"`
+	Workers           int     `koanf:"workers"`
+	VisibilityTimeout float64 `koanf:"visibility_timeout"`
+	PollInterval      float64 `koanf:"poll_interval"`
+}
+
+// Lease is the configuration for run leases. An instance holds the lease of
+// every run it executes for Duration and renews it three times per Duration.
+// Every SweepInterval, each instance looks for runs whose lease expired, or
+// that were left PENDING, and queues them again. Durations are in seconds.
+type Lease struct {
+	Duration      float64 `koanf:"duration"`
+	SweepInterval float64 `koanf:"sweep_interval"`
+}
+
+// Scheduler is the configuration for delayed and scheduled runs. Every
+// PollInterval seconds, each instance queues the scheduled runs that are due
+// and fires the cron schedules that are due.
+type Scheduler struct {
+	PollInterval float64 `koanf:"poll_interval"`
+}
+
+// Shutdown is the configuration for graceful shutdown. Runs being executed
+// get DrainTimeout seconds to finish the steps in flight before they are
+// interrupted.
+type Shutdown struct {
+	DrainTimeout float64 `koanf:"drain_timeout"`
+}
+
+// Flows points at a directory of declarative flow definitions (YAML or JSON)
+// that are registered alongside the code-defined flows. Empty loads none.
+type Flows struct {
+	Dir string `koanf:"dir"`
 }
 
 // Executor is the configuration for the executor service.
-// Backoff durations are in seconds in YAML and converted to time.Duration.
+// Flow is the flow used by runs created without naming one (POST /runs).
+// The retry settings are the defaults for steps without their own policy.
+// Durations are in seconds in YAML (fractions allowed, e.g. 0.5) and
+// converted to time.Duration.
 type Executor struct {
 	Flow           string  `koanf:"flow"`
 	MaxRetries     int     `koanf:"max_retries"`
-	InitialBackoff int     `koanf:"initial_backoff"` // seconds
-	MaxBackoff     int     `koanf:"max_backoff"`     // seconds
-	BackoffFactor  float64 `koanf:"backoff_factor"`
-	JitterFraction float64 `koanf:"jitter_fraction"` // 0.0 to 1.0
+	InitialBackoff float64 `koanf:"initial_backoff"`  // seconds
+	MaxBackoff     float64 `koanf:"max_backoff"`      // seconds
+	BackoffFactor  float64 `koanf:"backoff_factor"`   // >= 1.0
+	JitterFraction float64 `koanf:"jitter_fraction"`  // 0.0 to 1.0
+	MaxElapsedTime float64 `koanf:"max_elapsed_time"` // seconds, 0 means no limit
+	StepTimeout    float64 `koanf:"step_timeout"`     // seconds per attempt, 0 means no limit
+}
+
+// RetryPolicy returns the executor's default retry policy.
+func (e Executor) RetryPolicy() flow.RetryPolicy {
+	jitter := e.JitterFraction
+	return flow.RetryPolicy{
+		MaxAttempts:    e.MaxRetries,
+		InitialBackoff: helpers.Seconds(e.InitialBackoff),
+		MaxBackoff:     helpers.Seconds(e.MaxBackoff),
+		BackoffFactor:  e.BackoffFactor,
+		JitterFraction: &jitter,
+		MaxElapsedTime: helpers.Seconds(e.MaxElapsedTime),
+	}
 }
 
 type Endpoint struct {
@@ -104,6 +194,16 @@ func (c *Config) Validate() error {
 	// Required Numeric Fields
 	helpers.ValidateRequiredNumber(ve, "queue.size", c.Queue.Size)
 	helpers.ValidateRequiredNumber(ve, "queue.workers", c.Queue.Workers)
+	helpers.ValidateRequiredNumber(ve, "queue.visibility_timeout", c.Queue.VisibilityTimeout)
+	helpers.ValidateRequiredNumber(ve, "queue.poll_interval", c.Queue.PollInterval)
+	helpers.ValidateRequiredNumber(ve, "lease.duration", c.Lease.Duration)
+	helpers.ValidateRequiredNumber(ve, "lease.sweep_interval", c.Lease.SweepInterval)
+	helpers.ValidateRequiredNumber(ve, "scheduler.poll_interval", c.Scheduler.PollInterval)
+	helpers.ValidateRequiredNumber(ve, "shutdown.drain_timeout", c.Shutdown.DrainTimeout)
+
+	if c.Queue.Type != QueueMongo && c.Queue.Type != QueueMemory {
+		ve.Add("queue.type", "must be either mongo or memory")
+	}
 
 	// Executor Fields
 	helpers.ValidateRequiredString(ve, "executor.flow", c.Executor.Flow)
@@ -120,6 +220,30 @@ func (c *Config) Validate() error {
 	if c.Executor.JitterFraction < 0 || c.Executor.JitterFraction > 1.0 {
 		ve.Add("executor.jitter_fraction", "must be between 0 and 1")
 	}
+	if c.Executor.MaxBackoff < c.Executor.InitialBackoff {
+		ve.Add("executor.max_backoff", "must be >= executor.initial_backoff")
+	}
+	if c.Executor.MaxElapsedTime < 0 {
+		ve.Add("executor.max_elapsed_time", "cannot be negative")
+	}
+	if c.Executor.StepTimeout < 0 {
+		ve.Add("executor.step_timeout", "cannot be negative")
+	}
+
+	// Schedules
+	names := make(map[string]bool)
+	for i, def := range c.Schedules {
+		field := fmt.Sprintf("schedules[%d].", i)
+		def.SetDefaults()
+		def.Validate(ve, field)
+		if def.Flow != "" && !flow.Exists(def.Flow) {
+			ve.Add(field+"flow", fmt.Sprintf("%s not found in registry", def.Flow))
+		}
+		if names[def.Name] {
+			ve.Add(field+"name", "is used by another schedule")
+		}
+		names[def.Name] = true
+	}
 
 	return ve.Err()
 }
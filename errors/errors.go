@@ -48,6 +48,7 @@ const (
 	NotFound                 // Entity does not exist
 	Unauthorized             // Unauthorized access
 	Forbidden                // Forbidden access
+	Unavailable              // Temporarily unable to serve, e.g. shutting down
 )
 
 func (k Kind) String() string {
@@ -60,6 +61,10 @@ func (k Kind) String() string {
 		return "invalid input"
 	case NotFound:
 		return "entity not found"
+	case Conflict:
+		return "conflict"
+	case Unavailable:
+		return "unavailable"
 	default:
 		return "unknown error kind"
 	}
@@ -87,6 +92,7 @@ func E(args ...interface{}) error {
 }
 
 var (
-	As = errors.As
-	Is = errors.Is
+	As   = errors.As
+	Is   = errors.Is
+	Join = errors.Join
 )
@@ -11,49 +11,86 @@ import (
 var DefaultFlow = Flow{
 	Name: "default_flow",
 	Steps: []Step{
-		{
-			Name:        "validate_input",
-			Description: "Validates the incoming input payload",
-			Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
-				time.Sleep(2 * time.Second)
+		validateInput([]string{"name"}),
+		processData,
+		sendNotification,
+	},
+}
 
-				name, ok := input["name"]
+// validateInput builds a step that fails unless every required field is
+// present in its input, and passes those fields on. A missing field is a
+// permanent error, since retrying with the same input cannot fix it.
+func validateInput(required []string) Step {
+	return Step{
+		Name:        "validate_input",
+		Description: "Validates the incoming input payload",
+		Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			time.Sleep(2 * time.Second)
+
+			output := make(map[string]any, len(required)+1)
+			for _, field := range required {
+				value, ok := input[field]
 				if !ok {
-					return nil, fmt.Errorf("missing required field: name")
+					return nil, Permanent(fmt.Errorf("missing required field: %s", field))
 				}
+				output[field] = value
+			}
 
-				return map[string]any{
-					"name":         name,
-					"validated_at": time.Now().UTC().Format(time.RFC3339),
-				}, nil
-			},
-		},
-		{
-			Name:        "process_data",
-			Description: "Processes the validated data",
-			Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
-				time.Sleep(3 * time.Second)
-
-				return map[string]any{
-					"name":         input["name"],
-					"processed":    true,
-					"processed_at": time.Now().UTC().Format(time.RFC3339),
-				}, nil
-			},
-		},
-		{
-			Name:        "send_notification",
-			Description: "Sends a completion notification",
-			Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
-				time.Sleep(1 * time.Second)
-
-				fmt.Printf("[send_notification] Notification sent for: %v\n", input["name"])
-				return map[string]any{
-					"name":      input["name"],
-					"notified":  true,
-					"completed": true,
-				}, nil
-			},
+			output["validated_at"] = time.Now().UTC().Format(time.RFC3339)
+			return output, nil
 		},
+	}
+}
+
+var processData = Step{
+	Name:        "process_data",
+	Description: "Processes the validated data",
+	Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+		time.Sleep(3 * time.Second)
+
+		return map[string]any{
+			"name":         input["name"],
+			"processed":    true,
+			"processed_at": time.Now().UTC().Format(time.RFC3339),
+		}, nil
 	},
 }
+
+var sendNotification = Step{
+	Name:        "send_notification",
+	Description: "Sends a completion notification",
+	Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+		time.Sleep(1 * time.Second)
+
+		fmt.Printf("[send_notification] Notification sent for: %v\n", input["name"])
+		return map[string]any{
+			"name":      input["name"],
+			"notified":  true,
+			"completed": true,
+		}, nil
+	},
+}
+
+// newValidateInput is the validate_input step type. The "required" param
+// lists the fields to check for and defaults to just "name".
+func newValidateInput(params map[string]any) (Step, error) {
+	raw, ok := params["required"]
+	if !ok {
+		return validateInput([]string{"name"}), nil
+	}
+
+	list, ok := raw.([]any)
+	if !ok {
+		return Step{}, fmt.Errorf("param required must be a list of field names")
+	}
+
+	required := make([]string, len(list))
+	for i, v := range list {
+		field, ok := v.(string)
+		if !ok {
+			return Step{}, fmt.Errorf("param required must be a list of field names")
+		}
+		required[i] = field
+	}
+	return validateInput(required), nil
+}
@@ -0,0 +1,157 @@
+package flow
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+
+	// External Packages
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+)
+
+// Definition is the declarative form of a flow, loaded from a YAML or JSON
+// file. Its steps reference registered step types by name, so new flows can
+// be composed from existing steps without a code change.
+type Definition struct {
+	Name  string           `koanf:"name"`
+	Steps []StepDefinition `koanf:"steps"`
+}
+
+// StepDefinition declares one step of a Definition. Type names the step type
+// that implements it and Params are passed to that type. A step that lists
+// Parallel members is a group and has no type of its own.
+type StepDefinition struct {
+	Name        string           `koanf:"name"`
+	Type        string           `koanf:"type"`
+	Description string           `koanf:"description"`
+	Params      map[string]any   `koanf:"params"`
+	DependsOn   []string         `koanf:"depends_on"`
+	Retry       *RetryPolicy     `koanf:"retry"`
+	Timeout     time.Duration    `koanf:"timeout"`
+	Parallel    []StepDefinition `koanf:"parallel"`
+}
+
+// Build resolves the definition's step types and returns the resulting Flow.
+// Every problem found is reported as a validation error.
+func (d *Definition) Build() (Flow, error) {
+	ve := errors.ValidationErrs()
+	if d.Name == "" {
+		ve.Add("name", "cannot be empty")
+	}
+	if len(d.Steps) == 0 {
+		ve.Add(d.Name+".steps", "cannot be empty")
+	}
+
+	steps := make([]Step, 0, len(d.Steps))
+	for _, sd := range d.Steps {
+		steps = append(steps, sd.build(ve, d.Name))
+	}
+
+	if err := ve.Err(); err != nil {
+		return Flow{}, err
+	}
+	return Flow{Name: d.Name, Steps: steps}, nil
+}
+
+// build resolves a single step definition, adding any problem to ve.
+func (sd *StepDefinition) build(ve *errors.ValidationErrorBuilder, prefix string) Step {
+	field := prefix + "." + sd.Name
+	if sd.Name == "" {
+		ve.Add(prefix, "step name cannot be empty")
+	}
+
+	var step Step
+	switch {
+	case len(sd.Parallel) > 0:
+		if sd.Type != "" {
+			ve.Add(field, "a parallel group cannot have a type")
+		}
+		for _, member := range sd.Parallel {
+			step.Parallel = append(step.Parallel, member.build(ve, prefix))
+		}
+	case sd.Type == "":
+		ve.Add(field, "type cannot be empty")
+	default:
+		newStep, ok := stepTypes[sd.Type]
+		if !ok {
+			ve.Add(field, fmt.Sprintf("unknown step type %s", sd.Type))
+			break
+		}
+
+		var err error
+		if step, err = newStep(sd.Params); err != nil {
+			ve.Add(field, err.Error())
+		}
+	}
+
+	step.Name = sd.Name
+	if sd.Description != "" {
+		step.Description = sd.Description
+	}
+	step.DependsOn = sd.DependsOn
+	step.Retry = sd.Retry
+	step.Timeout = sd.Timeout
+	return step
+}
+
+// LoadDir reads every .yml, .yaml and .json file in dir as a flow definition
+// and registers the resulting flows. An empty dir loads nothing.
+func LoadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read flow definitions: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yml", ".yaml", ".json":
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if err = LoadFile(path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LoadFile reads a single flow definition and registers it under its name.
+func LoadFile(path string) error {
+	var parser koanf.Parser = yaml.Parser()
+	if filepath.Ext(path) == ".json" {
+		parser = json.Parser()
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), parser); err != nil {
+		return err
+	}
+
+	var def Definition
+	if err := k.Unmarshal("", &def); err != nil {
+		return err
+	}
+
+	f, err := def.Build()
+	if err != nil {
+		return err
+	}
+	return Register(def.Name, f)
+}
@@ -0,0 +1,71 @@
+package flow
+
+import (
+	// Go Internal Packages
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+)
+
+// PermanentError wraps a step error that retrying cannot fix, such as invalid
+// input. The executor fails the step immediately, without further attempts.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent marks err as permanent. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfterError wraps a step error together with how long to wait before
+// the next attempt, e.g. the Retry-After of an upstream 429. The delay
+// replaces the backoff of the step's retry policy for that attempt only.
+type RetryAfterError struct {
+	Err   error
+	Delay time.Duration
+}
+
+// RetryAfter asks for the step to be retried after delay. It returns nil if
+// err is nil.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Err: err, Delay: delay}
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether err, or any error it wraps, is permanent.
+func IsPermanent(err error) bool {
+	var pe *PermanentError
+	return errors.As(err, &pe)
+}
+
+// RetryDelay returns the delay requested by a RetryAfterError in err's chain.
+func RetryDelay(err error) (time.Duration, bool) {
+	var re *RetryAfterError
+	if !errors.As(err, &re) {
+		return 0, false
+	}
+	return re.Delay, true
+}
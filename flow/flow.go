@@ -3,21 +3,160 @@ package flow
 import (
 	// Go Internal Packages
 	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
 )
 
 // Step represents a single unit of work within a flow.
-// Each step has a name, an optional cleanup phase, and an execution phase.
+// Each step has a name, an execution phase and optional lifecycle hooks.
 // The output of one step becomes the input of the next.
 type Step struct {
 	Name        string
 	Description string
-	Cleanup     func(ctx context.Context, input map[string]any) error
 	Execute     func(ctx context.Context, input map[string]any) (map[string]any, error)
+
+	// BeforeAttempt, if set, runs right before every attempt of Execute,
+	// including the first one, to reset state left behind by an earlier
+	// attempt or an interrupted run. It is part of the attempt: if it fails,
+	// Execute is not called and the attempt fails with its error.
+	BeforeAttempt func(ctx context.Context, input map[string]any) error
+
+	// AfterSuccess, if set, runs once after the step has completed and its
+	// output was recorded. Its error is logged but does not fail the step.
+	AfterSuccess func(ctx context.Context, input, output map[string]any) error
+
+	// OnFailure, if set, runs once after the step has failed for good, with
+	// the error of its last attempt. It does not run for a step interrupted
+	// by cancellation. Its error is logged and otherwise ignored.
+	OnFailure func(ctx context.Context, input map[string]any, err error) error
+
+	// DependsOn names the steps (or parallel groups) that must complete before
+	// this one starts. The step's input is the merged output of those steps,
+	// in the order listed. See Flow for how flows without DependsOn behave.
+	DependsOn []string
+
+	// When, if set, is evaluated against the step's input right before it
+	// would execute. If it returns false the step is recorded as SKIPPED and
+	// its input is passed through unchanged as its output.
+	When func(ctx context.Context, input map[string]any) (bool, error)
+
+	// Branch, if set, is evaluated against the step's output once it completes
+	// and returns the name of the dependent step to follow. The step's other
+	// dependents are recorded as SKIPPED, as is everything downstream of them
+	// that has no other dependency still in play.
+	Branch func(ctx context.Context, output map[string]any) (string, error)
+
+	// Compensate, if set, undoes the step's effects when its run fails after
+	// the step completed. It receives the step's persisted output. The
+	// compensations of a failed run execute in reverse flow order, so a step
+	// is compensated before the steps it depends on, with the step's retry
+	// policy.
+	Compensate func(ctx context.Context, output map[string]any) error
+
+	// Retry overrides the executor's retry settings for this step.
+	Retry *RetryPolicy
+
+	// Timeout limits each attempt of the step, overriding the executor's
+	// default. BeforeAttempt and Execute receive a context that expires with
+	// it; an attempt still running when it does is abandoned and counts as a
+	// failure.
+	Timeout time.Duration
+
+	// Parallel turns the step into a group of independent steps that execute
+	// concurrently with the same input. Their outputs are merged in
+	// declaration order (later members win on key clashes) to form the input
+	// of the next step, and the group fails if any member fails.
+	// Execute, the lifecycle hooks, Compensate, Retry and Timeout are ignored
+	// on a group; groups cannot be nested.
+	Parallel []Step
+}
+
+// RetryPolicy controls how a failing step is retried: how many attempts it
+// gets, the exponential backoff curve between them, the jitter applied to
+// each backoff, and an optional cap on the total time spent retrying.
+// On a step, unset (zero) fields fall back to the executor configuration.
+type RetryPolicy struct {
+	MaxAttempts    int           `koanf:"max_attempts"`
+	InitialBackoff time.Duration `koanf:"initial_backoff"`
+	MaxBackoff     time.Duration `koanf:"max_backoff"`
+	BackoffFactor  float64       `koanf:"backoff_factor"`
+	JitterFraction *float64      `koanf:"jitter_fraction"`  // nil falls back, 0 disables jitter
+	MaxElapsedTime time.Duration `koanf:"max_elapsed_time"` // 0 means no limit
+}
+
+// Resolve returns the policy with every unset field taken from defaults.
+// It is safe to call on a nil policy, which yields defaults.
+func (p *RetryPolicy) Resolve(defaults RetryPolicy) RetryPolicy {
+	if p == nil {
+		return defaults
+	}
+
+	resolved := *p
+	if resolved.MaxAttempts <= 0 {
+		resolved.MaxAttempts = defaults.MaxAttempts
+	}
+	if resolved.InitialBackoff <= 0 {
+		resolved.InitialBackoff = defaults.InitialBackoff
+	}
+	if resolved.MaxBackoff <= 0 {
+		resolved.MaxBackoff = defaults.MaxBackoff
+	}
+	if resolved.BackoffFactor < 1.0 {
+		resolved.BackoffFactor = defaults.BackoffFactor
+	}
+	if resolved.JitterFraction == nil {
+		resolved.JitterFraction = defaults.JitterFraction
+	}
+	if resolved.MaxElapsedTime <= 0 {
+		resolved.MaxElapsedTime = defaults.MaxElapsedTime
+	}
+	return resolved
+}
+
+// validate reports settings that can never be satisfied. A nil policy is valid.
+func (p *RetryPolicy) validate(ve *errors.ValidationErrorBuilder, field string) {
+	if p == nil {
+		return
+	}
+	if p.MaxAttempts < 0 {
+		ve.Add(field+".retry.max_attempts", "cannot be negative")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.MaxElapsedTime < 0 {
+		ve.Add(field+".retry", "durations cannot be negative")
+	}
+	if p.BackoffFactor != 0 && p.BackoffFactor < 1.0 {
+		ve.Add(field+".retry.backoff_factor", "must be >= 1.0")
+	}
+	if p.JitterFraction != nil && (*p.JitterFraction < 0 || *p.JitterFraction > 1.0) {
+		ve.Add(field+".retry.jitter_fraction", "must be between 0 and 1")
+	}
+}
+
+// Jitter returns the jitter fraction, treating nil as no jitter.
+func (p RetryPolicy) Jitter() float64 {
+	if p.JitterFraction == nil {
+		return 0
+	}
+	return *p.JitterFraction
+}
+
+// IsGroup returns true if the step is a parallel group.
+func (s Step) IsGroup() bool {
+	return len(s.Parallel) > 0
 }
 
 // Flow is a static, code-defined blueprint containing an ordered list of steps.
 // Flows are NOT persisted — they exist only in code. When a flow is triggered,
 // a Run (persisted) is created along with StepRuns for tracking execution state.
+//
+// If no step declares DependsOn, the steps run in the order listed, each
+// depending on the one before it. As soon as any step declares DependsOn, the
+// flow is a DAG: steps without DependsOn are roots that receive the run input,
+// and every step starts as soon as all of its dependencies have completed.
 type Flow struct {
 	Name  string
 	Steps []Step
@@ -28,36 +167,56 @@ func (f *Flow) GetAllSteps() []Step {
 	return f.Steps
 }
 
-// StepNames returns the names of all steps in order, useful for logging.
+// StepNames returns the names of all executable steps in order, useful for
+// logging. Parallel groups are expanded into their members.
 func (f *Flow) StepNames() []string {
-	names := make([]string, len(f.Steps))
-	for i, s := range f.Steps {
-		names[i] = s.Name
+	var names []string
+	for _, s := range f.Steps {
+		if !s.IsGroup() {
+			names = append(names, s.Name)
+			continue
+		}
+		for _, member := range s.Parallel {
+			names = append(names, member.Name)
+		}
 	}
 	return names
 }
 
-// GetPendingSteps returns all steps that still need to be executed,
-// starting from the last executed step. If the last step failed,
-// it is included in the pending list for retry.
-func (f *Flow) GetPendingSteps(lastStep string, lastSucceeded bool) []Step {
-	var remaining []Step
-	found := false
+// HasCompensation returns true if any executable step of the flow defines
+// Compensate.
+func (f *Flow) HasCompensation() bool {
+	for _, n := range f.Nodes() {
+		if n.Compensate != nil {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, step := range f.Steps {
-		if step.Name == lastStep {
-			found = true
-			if !lastSucceeded {
-				remaining = append(remaining, step)
-			}
+// GetPendingSteps returns the steps that still need to be executed given the
+// names of the steps that already completed, in declaration order. A step
+// that failed or never finished is pending, and so is every step depending
+// on it, even if it completed in an earlier execution.
+func (f *Flow) GetPendingSteps(completed map[string]bool) []Node {
+	nodes := f.Nodes()
+	pending := make(map[string]bool)
+	for _, n := range nodes {
+		if completed[n.Name] {
 			continue
 		}
-
-		if found {
-			remaining = append(remaining, step)
+		pending[n.Name] = true
+		for _, d := range f.Descendants(n.Name) {
+			pending[d] = true
 		}
 	}
 
+	var remaining []Node
+	for _, n := range nodes {
+		if pending[n.Name] {
+			remaining = append(remaining, n)
+		}
+	}
 	return remaining
 }
 
@@ -67,18 +226,73 @@ var registry = map[string]Flow{
 	"default": DefaultFlow,
 }
 
+// StepType is a reusable, code-defined step implementation that declarative
+// flow definitions reference by name. It receives the params given to the
+// step in the definition and returns the step to execute.
+type StepType func(params map[string]any) (Step, error)
+
+// Static returns a StepType that ignores params and always yields step.
+func Static(step Step) StepType {
+	return func(map[string]any) (Step, error) {
+		return step, nil
+	}
+}
+
+// stepTypes maps the names used in flow definitions to code-defined steps.
+// Add new step types here when they are created.
+var stepTypes = map[string]StepType{
+	"validate_input":    newValidateInput,
+	"process_data":      Static(processData),
+	"send_notification": Static(sendNotification),
+}
+
+// Register adds a flow to the registry under the given name after validating
+// it. Flows must be registered at startup, before any run is executed.
+func Register(name string, f Flow) error {
+	if Exists(name) {
+		return errors.E(errors.Invalid, fmt.Sprintf("flow %s is already registered", name))
+	}
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	registry[name] = f
+	return nil
+}
+
 // Exists checks if a flow with the given name is registered.
 func Exists(name string) bool {
 	_, ok := registry[name]
 	return ok
 }
 
-// Get returns the flow matching the given name.
-// If not Exists return Dummy flow
-func Get(name string) Flow {
+// ValidateRegistry checks every registered flow for duplicate step names,
+// unknown dependencies and cycles. Call it once at startup.
+func ValidateRegistry() error {
+	ve := errors.ValidationErrs()
+	for _, name := range Names() {
+		f := registry[name]
+		f.validate(ve, name)
+	}
+	return ve.Err()
+}
+
+// Names returns the names of all registered flows in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get returns the flow matching the given name, or a NotFound error if no
+// flow is registered under it.
+func Get(name string) (Flow, error) {
 	f, ok := registry[name]
 	if !ok {
-		return DefaultFlow
+		return Flow{}, errors.E(errors.NotFound, fmt.Sprintf("flow %s not found", name))
 	}
-	return f
+	return f, nil
 }
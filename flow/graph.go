@@ -0,0 +1,251 @@
+package flow
+
+import (
+	// Go Internal Packages
+	"fmt"
+	"slices"
+	"strings"
+
+	// Local Packages
+	errors "flowx/errors"
+)
+
+// Node is an executable step together with the names of the steps it depends
+// on, once parallel groups and implicit ordering have been resolved.
+type Node struct {
+	Step
+	Parents []string
+}
+
+// hasDependencies returns true if any step (or group member) declares DependsOn.
+func (f *Flow) hasDependencies() bool {
+	for _, s := range f.Steps {
+		if len(s.DependsOn) > 0 {
+			return true
+		}
+		for _, member := range s.Parallel {
+			if len(member.DependsOn) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Nodes resolves the flow into its executable steps, in declaration order.
+// Parallel groups are expanded into their members: each member inherits the
+// group's dependencies, and depending on a group means depending on all of
+// its members. Flows without DependsOn are chained in the order listed.
+func (f *Flow) Nodes() []Node {
+	groups := make(map[string][]string)
+	for _, s := range f.Steps {
+		for _, member := range s.Parallel {
+			groups[s.Name] = append(groups[s.Name], member.Name)
+		}
+	}
+
+	expand := func(names []string) []string {
+		var out []string
+		for _, name := range names {
+			if members, ok := groups[name]; ok {
+				out = append(out, members...)
+				continue
+			}
+			out = append(out, name)
+		}
+		return out
+	}
+
+	explicit := f.hasDependencies()
+	var nodes []Node
+	var previous []string
+
+	for _, s := range f.Steps {
+		parents := previous
+		if explicit {
+			parents = expand(s.DependsOn)
+		}
+
+		if !s.IsGroup() {
+			nodes = append(nodes, Node{Step: s, Parents: parents})
+			previous = []string{s.Name}
+			continue
+		}
+
+		for _, member := range s.Parallel {
+			memberParents := parents
+			if explicit {
+				memberParents = append(slices.Clone(parents), expand(member.DependsOn)...)
+			}
+			nodes = append(nodes, Node{Step: member, Parents: memberParents})
+		}
+		previous = groups[s.Name]
+	}
+	return nodes
+}
+
+// SortedNodes returns the nodes of the flow ordered so that every step comes
+// after the steps it depends on, otherwise keeping declaration order. This is
+// an order the steps can execute in.
+func (f *Flow) SortedNodes() []Node {
+	pending := f.Nodes()
+	sorted := make([]Node, 0, len(pending))
+	placed := make(map[string]bool, len(pending))
+
+	for len(pending) > 0 {
+		i := slices.IndexFunc(pending, func(n Node) bool {
+			for _, p := range n.Parents {
+				if !placed[p] {
+					return false
+				}
+			}
+			return true
+		})
+		// Validated flows have no cycles; keep the rest as declared if one does.
+		if i < 0 {
+			return append(sorted, pending...)
+		}
+		placed[pending[i].Name] = true
+		sorted = append(sorted, pending[i])
+		pending = slices.Delete(pending, i, i+1)
+	}
+	return sorted
+}
+
+// Descendants returns the names of every step that depends, directly or
+// transitively, on the named step.
+func (f *Flow) Descendants(stepName string) []string {
+	children := make(map[string][]string)
+	for _, n := range f.Nodes() {
+		for _, p := range n.Parents {
+			children[p] = append(children[p], n.Name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var out []string
+	queue := []string{stepName}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if !seen[child] {
+				seen[child] = true
+				out = append(out, child)
+				queue = append(queue, child)
+			}
+		}
+	}
+	return out
+}
+
+// Validate checks the flow for empty or duplicate step names, nested groups,
+// unknown or self dependencies, and dependency cycles.
+func (f *Flow) Validate() error {
+	ve := errors.ValidationErrs()
+	f.validate(ve, f.Name)
+	return ve.Err()
+}
+
+// validate adds every problem found in the flow to ve, prefixing fields with prefix.
+func (f *Flow) validate(ve *errors.ValidationErrorBuilder, prefix string) {
+	if len(f.Steps) == 0 {
+		ve.Add(prefix, "flow has no steps")
+		return
+	}
+
+	declared := make(map[string]bool)
+	declare := func(name string) {
+		if name == "" {
+			ve.Add(prefix, "step name cannot be empty")
+			return
+		}
+		if declared[name] {
+			ve.Add(prefix+"."+name, "duplicate step name")
+		}
+		declared[name] = true
+	}
+
+	for _, s := range f.Steps {
+		declare(s.Name)
+		for _, member := range s.Parallel {
+			declare(member.Name)
+			if member.IsGroup() {
+				ve.Add(prefix+"."+member.Name, "parallel groups cannot be nested")
+			}
+		}
+	}
+
+	checkDeps := func(s Step) {
+		for _, dep := range s.DependsOn {
+			switch {
+			case dep == s.Name:
+				ve.Add(prefix+"."+s.Name, "step cannot depend on itself")
+			case !declared[dep]:
+				ve.Add(prefix+"."+s.Name, fmt.Sprintf("depends on unknown step %s", dep))
+			}
+		}
+	}
+	checkSettings := func(s Step) {
+		s.Retry.validate(ve, prefix+"."+s.Name)
+		if s.Timeout < 0 {
+			ve.Add(prefix+"."+s.Name+".timeout", "cannot be negative")
+		}
+	}
+	for _, s := range f.Steps {
+		checkDeps(s)
+		checkSettings(s)
+		for _, member := range s.Parallel {
+			checkDeps(member)
+			checkSettings(member)
+		}
+	}
+
+	if ve.Len() > 0 {
+		return
+	}
+
+	if cycle := f.findCycle(); len(cycle) > 0 {
+		ve.Add(prefix, fmt.Sprintf("dependency cycle between steps: %s", strings.Join(cycle, ", ")))
+	}
+}
+
+// findCycle returns the steps left over after repeatedly removing steps with
+// no unresolved dependencies (Kahn's algorithm). They are empty for a DAG.
+func (f *Flow) findCycle() []string {
+	nodes := f.Nodes()
+	remaining := make(map[string]int, len(nodes))
+	children := make(map[string][]string)
+	for _, n := range nodes {
+		remaining[n.Name] = len(n.Parents)
+		for _, p := range n.Parents {
+			children[p] = append(children[p], n.Name)
+		}
+	}
+
+	var ready []string
+	for _, n := range nodes {
+		if remaining[n.Name] == 0 {
+			ready = append(ready, n.Name)
+		}
+	}
+	for len(ready) > 0 {
+		current := ready[0]
+		ready = ready[1:]
+		delete(remaining, current)
+		for _, child := range children[current] {
+			remaining[child]--
+			if remaining[child] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+
+	var cycle []string
+	for _, n := range nodes {
+		if _, ok := remaining[n.Name]; ok {
+			cycle = append(cycle, n.Name)
+		}
+	}
+	return cycle
+}
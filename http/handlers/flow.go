@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	// Go Internal Packages
+	"net/http"
+
+	// Local Packages
+	errors "flowx/errors"
+	catalog "flowx/services/catalog"
+
+	// External Packages
+	"github.com/go-chi/chi/v5"
+)
+
+// FlowService defines the contract the handler needs to describe flows.
+type FlowService interface {
+	List() []catalog.FlowInfo
+	Get(name string) (*catalog.FlowInfo, error)
+}
+
+// FlowHandler exposes HTTP endpoints for discovering registered flows.
+type FlowHandler struct {
+	svc FlowService
+}
+
+// NewFlowHandler creates a new FlowHandler backed by the given service.
+func NewFlowHandler(svc FlowService) *FlowHandler {
+	return &FlowHandler{svc: svc}
+}
+
+// List handles GET /flows — returns every registered flow with its steps.
+func (h *FlowHandler) List(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	return map[string]any{
+		"flows": h.svc.List(),
+	}, http.StatusOK, nil
+}
+
+// Get handles GET /flows/{name} — returns the flow's ordered steps with their
+// descriptions and retry settings.
+func (h *FlowHandler) Get(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("name")
+	}
+
+	info, err := h.svc.Get(name)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	return info, http.StatusOK, nil
+}
@@ -4,17 +4,40 @@ import (
 	// Go Internal Packages
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	// Local Packages
 	errors "flowx/errors"
+	evmodels "flowx/models/event"
+	models "flowx/models/run"
+	helpers "flowx/utils/helpers"
+
+	// External Packages
+	"github.com/go-chi/chi/v5"
 )
 
 // RunService defines the contract the handler needs from the run service layer.
 type RunService interface {
-	Create(ctx context.Context, input map[string]any) (string, error)
+	Create(ctx context.Context, flowName string, input map[string]any, opts models.CreateOptions) (*models.Run, bool, error)
+	Wait(ctx context.Context, runID string, timeout time.Duration) (*models.Run, error)
+	Get(ctx context.Context, runID string) (*models.Details, error)
+	Subscribe(runID string) (<-chan evmodels.Event, func())
+	Stopping() <-chan struct{}
+	List(ctx context.Context, query models.ListQuery) (*models.ListResult, error)
+	Cancel(ctx context.Context, runID string) error
+	Retry(ctx context.Context, runID string) error
+	Rerun(ctx context.Context, runID, fromStep string) error
 }
 
+// IdempotencyKeyHeader is the request header carrying an idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// keepAliveInterval is how often an event stream checks on its run and, if
+// idle, sends a comment so that proxies do not close it.
+const keepAliveInterval = 15 * time.Second
+
 // RunHandler exposes HTTP endpoints for run operations.
 type RunHandler struct {
 	svc RunService
@@ -25,20 +48,279 @@ func NewRunHandler(svc RunService) *RunHandler {
 	return &RunHandler{svc: svc}
 }
 
-// Create handles POST /runs — decodes the input payload, creates a new run,
-// and returns the generated run ID.
+// Create handles POST /runs and POST /flows/{name}/runs — decodes the input
+// payload, creates a new run of the named flow (or the default flow when no
+// name is in the path), and returns the generated run ID. An optional
+// ?timeout= sets a deadline for the whole run, and ?run_at= or ?delay=
+// schedule it to start later. A request repeated with the same
+// Idempotency-Key header (or ?idempotency_key=) returns the run created the
+// first time with 200 instead of creating another one. With ?wait= the
+// request waits for the run to finish, see wait.
 func (h *RunHandler) Create(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	var opts models.CreateOptions
+	if err = helpers.GetSchemaDecoder().Decode(&opts, r.URL.Query()); err != nil {
+		return nil, http.StatusBadRequest, errors.InvalidParamsErr(err)
+	}
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		if opts.IdempotencyKey != "" && opts.IdempotencyKey != key {
+			return nil, http.StatusBadRequest, errors.E(errors.Invalid, "Idempotency-Key header and idempotency_key differ")
+		}
+		opts.IdempotencyKey = key
+	}
+	if err = opts.Validate(); err != nil {
+		return nil, http.StatusBadRequest, errors.ValidationFailedErr(err)
+	}
+
 	var input map[string]any
 	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
 		return nil, http.StatusBadRequest, errors.InvalidBodyErr(err)
 	}
 
-	runID, err := h.svc.Create(r.Context(), input)
-	if err == nil {
+	flowName := chi.URLParam(r, "name")
+	run, created, err := h.svc.Create(r.Context(), flowName, input, opts)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if wait := opts.WaitDuration(); wait > 0 {
+		return h.wait(r.Context(), run.ID, wait)
+	}
+	if !created {
+		return map[string]any{
+			"message": "Run Already Created With This Idempotency Key",
+			"run_id":  run.ID,
+			"status":  run.Status,
+		}, http.StatusOK, nil
+	}
+	return map[string]any{
+		"message": "Run Created Successfully!",
+		"run_id":  run.ID,
+	}, http.StatusCreated, nil
+}
+
+// wait waits up to timeout for a run to finish. A finished run is returned
+// with 200 along with its output, or the reason it did not complete; a run
+// still in progress by then, or when the server starts shutting down, is
+// returned with 202. A run executed by another instance is noticed finishing
+// with a delay, see RunService.Wait.
+func (h *RunHandler) wait(ctx context.Context, runID string, timeout time.Duration) (response any, status int, err error) {
+	run, err := h.svc.Wait(ctx, runID, timeout)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	switch run.Status {
+	case models.StatusCompleted:
+		return map[string]any{
+			"message": "Run Completed Successfully!",
+			"run_id":  run.ID,
+			"status":  run.Status,
+			"output":  run.Output,
+		}, http.StatusOK, nil
+	case models.StatusFailed, models.StatusCancelled:
 		return map[string]any{
-			"message": "Run Created Successfully!",
-			"run_id":  runID,
-		}, http.StatusCreated, nil
+			"message": "Run Did Not Complete",
+			"run_id":  run.ID,
+			"status":  run.Status,
+			"reason":  run.Reason,
+		}, http.StatusOK, nil
+	default:
+		return map[string]any{
+			"message": "Run Still In Progress",
+			"run_id":  run.ID,
+			"status":  run.Status,
+		}, http.StatusAccepted, nil
+	}
+}
+
+// Get handles GET /runs/{id} — returns the run along with a timeline of its
+// step runs (input, ending state, reason, output and duration per step).
+func (h *RunHandler) Get(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	runID := chi.URLParam(r, "id")
+	if runID == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("id")
+	}
+
+	details, err := h.svc.Get(r.Context(), runID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return details, http.StatusOK, nil
+}
+
+// Events handles GET /runs/{id}/events — streams the progress of a run as
+// server-sent events. The stream opens with a "snapshot" event holding the
+// run and its step runs, as returned by Get, followed by an event per step
+// started, attempt failed, step completed, failed or skipped, and ends with
+// the event of the run finishing. A run that already finished ends the
+// stream right after the snapshot. Only runs executing on this instance
+// produce step events; a run executed by another instance is noticed
+// finishing when its status is checked every keepAliveInterval. Streams end
+// when the server starts shutting down.
+func (h *RunHandler) Events(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	runID := chi.URLParam(r, "id")
+	if runID == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("id")
+	}
+
+	// Subscribe before taking the snapshot so that no event is missed.
+	events, unsubscribe := h.svc.Subscribe(runID)
+	defer unsubscribe()
+
+	details, err := h.svc.Get(r.Context(), runID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err = writeEvent(w, rc, "snapshot", details); err != nil || details.Run.Status.IsTerminal() {
+		return nil, 0, nil
+	}
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil, 0, nil
+		case <-h.svc.Stopping():
+			return nil, 0, nil
+		case <-ticker.C:
+			details, err = h.svc.Get(r.Context(), runID)
+			if err != nil {
+				return nil, 0, nil
+			}
+			if details.Run.Status.IsTerminal() {
+				event := finishedEvent(details.Run)
+				_ = writeEvent(w, rc, string(event.Type), event)
+				return nil, 0, nil
+			}
+			if _, err = fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil, 0, nil
+			}
+			err = rc.Flush()
+		case event, ok := <-events:
+			// A closed channel means the stream fell behind; the client
+			// reconnects and starts over from a new snapshot.
+			if !ok {
+				return nil, 0, nil
+			}
+			err = writeEvent(w, rc, string(event.Type), event)
+			if err == nil && event.Type.IsTerminal() {
+				return nil, 0, nil
+			}
+		}
+		if err != nil {
+			return nil, 0, nil
+		}
+	}
+}
+
+// finishedEvent returns the event of a run reaching its terminal status, for
+// a run that finished without this instance publishing it.
+func finishedEvent(run models.Run) evmodels.Event {
+	event := evmodels.Event{RunID: run.ID, Reason: run.Reason, Time: run.CompletedAt}
+	switch run.Status {
+	case models.StatusCompleted:
+		event.Type, event.Output = evmodels.RunCompleted, run.Output
+	case models.StatusCancelled:
+		event.Type = evmodels.RunCancelled
+	default:
+		event.Type = evmodels.RunFailed
+	}
+	return event
+}
+
+// writeEvent writes a server-sent event with data encoded as JSON, and
+// flushes it to the client.
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, name string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, b); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// List handles GET /runs — returns a page of runs filtered by completion
+// status, created_at range, flow name and last step status. Pass the
+// returned next_cursor back as ?cursor= to fetch the following page.
+func (h *RunHandler) List(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	var query models.ListQuery
+	if err = helpers.GetSchemaDecoder().Decode(&query, r.URL.Query()); err != nil {
+		return nil, http.StatusBadRequest, errors.InvalidParamsErr(err)
+	}
+
+	query.SetDefaults()
+	if err = query.Validate(); err != nil {
+		return nil, http.StatusBadRequest, errors.ValidationFailedErr(err)
+	}
+
+	result, err := h.svc.List(r.Context(), query)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return result, http.StatusOK, nil
+}
+
+// Cancel handles POST /runs/{id}/cancel — stops a queued or running run.
+func (h *RunHandler) Cancel(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	runID := chi.URLParam(r, "id")
+	if runID == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("id")
+	}
+
+	if err = h.svc.Cancel(r.Context(), runID); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"message": "Run Cancelled Successfully!",
+		"run_id":  runID,
+	}, http.StatusOK, nil
+}
+
+// Retry handles POST /runs/{id}/retry — re-enqueues a failed or cancelled run,
+// resuming from the step that did not complete.
+func (h *RunHandler) Retry(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	runID := chi.URLParam(r, "id")
+	if runID == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("id")
+	}
+
+	if err = h.svc.Retry(r.Context(), runID); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"message": "Run Requeued Successfully!",
+		"run_id":  runID,
+	}, http.StatusAccepted, nil
+}
+
+// Rerun handles POST /runs/{id}/rerun?from=<step> — re-enqueues a finished run
+// to execute again from the given step, using that step's recorded input.
+func (h *RunHandler) Rerun(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	runID := chi.URLParam(r, "id")
+	if runID == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("id")
+	}
+
+	fromStep := r.URL.Query().Get("from")
+	if fromStep == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("from")
+	}
+
+	if err = h.svc.Rerun(r.Context(), runID, fromStep); err != nil {
+		return nil, http.StatusInternalServerError, err
 	}
-	return
+	return map[string]any{
+		"message": "Run Requeued Successfully!",
+		"run_id":  runID,
+		"from":    fromStep,
+	}, http.StatusAccepted, nil
 }
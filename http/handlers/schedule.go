@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	// Go Internal Packages
+	"context"
+	"encoding/json"
+	"net/http"
+
+	// Local Packages
+	errors "flowx/errors"
+	models "flowx/models/schedule"
+
+	// External Packages
+	"github.com/go-chi/chi/v5"
+)
+
+// ScheduleService defines the contract the handler needs to manage schedules.
+type ScheduleService interface {
+	Create(ctx context.Context, def models.Definition) (*models.Schedule, error)
+	Get(ctx context.Context, name string) (*models.Schedule, error)
+	List(ctx context.Context) ([]models.Schedule, error)
+	Delete(ctx context.Context, name string) error
+	Pause(ctx context.Context, name string) error
+	Resume(ctx context.Context, name string) error
+}
+
+// ScheduleHandler exposes HTTP endpoints for cron schedules.
+type ScheduleHandler struct {
+	svc ScheduleService
+}
+
+// NewScheduleHandler creates a new ScheduleHandler backed by the given service.
+func NewScheduleHandler(svc ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{svc: svc}
+}
+
+// Create handles POST /schedules — creates a schedule from the definition in
+// the body, which fires first on the next tick of its cron expression.
+func (h *ScheduleHandler) Create(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	var def models.Definition
+	if err = json.NewDecoder(r.Body).Decode(&def); err != nil {
+		return nil, http.StatusBadRequest, errors.InvalidBodyErr(err)
+	}
+
+	schedule, err := h.svc.Create(r.Context(), def)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return schedule, http.StatusCreated, nil
+}
+
+// List handles GET /schedules — returns every schedule with its next tick.
+func (h *ScheduleHandler) List(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	schedules, err := h.svc.List(r.Context())
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"schedules": schedules,
+	}, http.StatusOK, nil
+}
+
+// Get handles GET /schedules/{name} — returns the schedule along with its
+// next tick and the last run it created.
+func (h *ScheduleHandler) Get(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("name")
+	}
+
+	schedule, err := h.svc.Get(r.Context(), name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return schedule, http.StatusOK, nil
+}
+
+// Delete handles DELETE /schedules/{name} — removes a schedule created
+// through the API. The runs it created are kept.
+func (h *ScheduleHandler) Delete(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("name")
+	}
+
+	if err = h.svc.Delete(r.Context(), name); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"message":  "Schedule Deleted Successfully!",
+		"schedule": name,
+	}, http.StatusOK, nil
+}
+
+// Pause handles POST /schedules/{name}/pause — stops the schedule from firing.
+func (h *ScheduleHandler) Pause(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("name")
+	}
+
+	if err = h.svc.Pause(r.Context(), name); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"message":  "Schedule Paused Successfully!",
+		"schedule": name,
+	}, http.StatusOK, nil
+}
+
+// Resume handles POST /schedules/{name}/resume — lets a paused schedule fire
+// again from its next tick, without catching up the ticks it missed.
+func (h *ScheduleHandler) Resume(w http.ResponseWriter, r *http.Request) (response any, status int, err error) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		return nil, http.StatusBadRequest, errors.EmptyParamErr("name")
+	}
+
+	if err = h.svc.Resume(r.Context(), name); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return map[string]any{
+		"message":  "Schedule Resumed Successfully!",
+		"schedule": name,
+	}, http.StatusOK, nil
+}
@@ -28,6 +28,8 @@ func RespondError(w http.ResponseWriter, err *errors.Error) {
 	switch err.Kind {
 	case errors.NotFound:
 		RespondMessage(w, http.StatusNotFound, err.Message)
+	case errors.Conflict:
+		RespondMessage(w, http.StatusConflict, err.Message)
 	case errors.Invalid:
 		var ve errors.ValidationErrors
 		if errors.As(err, &ve) {
@@ -46,6 +48,8 @@ func RespondError(w http.ResponseWriter, err *errors.Error) {
 		RespondMessage(w, http.StatusUnauthorized, err.Message)
 	case errors.Forbidden:
 		RespondMessage(w, http.StatusForbidden, err.Message)
+	case errors.Unavailable:
+		RespondMessage(w, http.StatusServiceUnavailable, err.Message)
 	default:
 		RespondMessage(w, http.StatusInternalServerError, err.Message)
 	}
@@ -20,11 +20,13 @@ import (
 
 // Server is the HTTP server that wires middleware, routes, and handlers.
 type Server struct {
-	close  func()
-	prefix string
-	logger *zap.Logger
-	health *handlers.HealthCheckHandler
-	run    *handlers.RunHandler
+	close    func()
+	prefix   string
+	logger   *zap.Logger
+	health   *handlers.HealthCheckHandler
+	run      *handlers.RunHandler
+	flow     *handlers.FlowHandler
+	schedule *handlers.ScheduleHandler
 }
 
 // NewServer creates a Server with all handler dependencies.
@@ -33,18 +35,24 @@ func NewServer(
 	prefix string,
 	health *handlers.HealthCheckHandler,
 	run *handlers.RunHandler,
+	flow *handlers.FlowHandler,
+	schedule *handlers.ScheduleHandler,
 	close func(),
 ) *Server {
 	return &Server{
-		close:  close,
-		logger: logger,
-		prefix: prefix,
-		health: health,
-		run:    run,
+		close:    close,
+		logger:   logger,
+		prefix:   prefix,
+		health:   health,
+		run:      run,
+		flow:     flow,
+		schedule: schedule,
 	}
 }
 
-// Listen starts the HTTP server and blocks until shutdown.
+// Listen starts the HTTP server and blocks until shutdown. Once ctx is done,
+// open requests get 5 seconds to complete before the close callback runs.
+// It only returns an error if the server fails to listen.
 func (s *Server) Listen(ctx context.Context, addr string) error {
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
@@ -56,6 +64,21 @@ func (s *Server) Listen(ctx context.Context, addr string) error {
 		r.Route("/v1", func(r chi.Router) {
 			r.Get("/health", s.ToHTTPHandlerFunc(s.health.HealthCheck))
 			r.Post("/runs", s.ToHTTPHandlerFunc(s.run.Create))
+			r.Get("/runs", s.ToHTTPHandlerFunc(s.run.List))
+			r.Get("/runs/{id}", s.ToHTTPHandlerFunc(s.run.Get))
+			r.Get("/runs/{id}/events", s.ToHTTPHandlerFunc(s.run.Events))
+			r.Post("/runs/{id}/cancel", s.ToHTTPHandlerFunc(s.run.Cancel))
+			r.Post("/runs/{id}/retry", s.ToHTTPHandlerFunc(s.run.Retry))
+			r.Post("/runs/{id}/rerun", s.ToHTTPHandlerFunc(s.run.Rerun))
+			r.Get("/flows", s.ToHTTPHandlerFunc(s.flow.List))
+			r.Get("/flows/{name}", s.ToHTTPHandlerFunc(s.flow.Get))
+			r.Post("/flows/{name}/runs", s.ToHTTPHandlerFunc(s.run.Create))
+			r.Get("/schedules", s.ToHTTPHandlerFunc(s.schedule.List))
+			r.Post("/schedules", s.ToHTTPHandlerFunc(s.schedule.Create))
+			r.Get("/schedules/{name}", s.ToHTTPHandlerFunc(s.schedule.Get))
+			r.Delete("/schedules/{name}", s.ToHTTPHandlerFunc(s.schedule.Delete))
+			r.Post("/schedules/{name}/pause", s.ToHTTPHandlerFunc(s.schedule.Pause))
+			r.Post("/schedules/{name}/resume", s.ToHTTPHandlerFunc(s.schedule.Resume))
 		})
 	})
 
@@ -72,8 +95,9 @@ func (s *Server) Listen(ctx context.Context, addr string) error {
 	case <-ctx.Done():
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		// Close even if requests are still open, so that runs are drained.
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			return err
+			s.logger.Warn("Requests Still Open After Shutdown Timeout", zap.Error(err))
 		}
 		if s.close != nil {
 			s.close()
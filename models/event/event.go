@@ -0,0 +1,33 @@
+package event
+
+// Type is the kind of progress an event reports.
+type Type string
+
+const (
+	StepStarted   Type = "step_started"   // A step began executing
+	AttemptFailed Type = "attempt_failed" // An attempt of a step failed and the step is retried after Backoff
+	StepCompleted Type = "step_completed" // A step succeeded
+	StepFailed    Type = "step_failed"    // A step failed for good
+	StepSkipped   Type = "step_skipped"   // A step was not executed, see Reason
+	RunCompleted  Type = "run_completed"  // Every step of the run succeeded
+	RunFailed     Type = "run_failed"     // The run failed, see Reason
+	RunCancelled  Type = "run_cancelled"  // The run was cancelled by an operator
+)
+
+// IsTerminal returns true for the events reporting that a run finished.
+func (t Type) IsTerminal() bool {
+	return t == RunCompleted || t == RunFailed || t == RunCancelled
+}
+
+// Event reports progress of a run as it executes. Step events carry the step
+// they concern and the attempt number, where one applies.
+type Event struct {
+	Type    Type           `json:"type"`
+	RunID   string         `json:"run_id"`
+	Step    string         `json:"step,omitempty"`
+	Attempt int            `json:"attempt,omitempty"`
+	Reason  string         `json:"reason,omitempty"`
+	Backoff string         `json:"backoff,omitempty"`
+	Output  map[string]any `json:"output,omitempty"`
+	Time    string         `json:"time"`
+}
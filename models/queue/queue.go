@@ -0,0 +1,19 @@
+package queue
+
+import (
+	// Go Internal Packages
+	"time"
+)
+
+// Item is a run waiting in the queue for a worker. Once a worker claims it,
+// Receipt identifies that claim and is required to extend or ack it; the item
+// stays hidden from other workers until VisibleAt. An item pushed again while
+// claimed is Requeued, and acking the claim makes it visible again.
+type Item struct {
+	RunID      string    `json:"run_id" bson:"_id"`
+	EnqueuedAt time.Time `json:"enqueued_at" bson:"enqueued_at"`
+	VisibleAt  time.Time `json:"visible_at" bson:"visible_at"`
+	Receipt    string    `json:"receipt,omitempty" bson:"receipt,omitempty"`
+	Deliveries int       `json:"deliveries" bson:"deliveries"` // Times the item was claimed
+	Requeued   bool      `json:"requeued" bson:"requeued"`     // Pushed again while claimed
+}
@@ -0,0 +1,118 @@
+package run
+
+import (
+	// Go Internal Packages
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+)
+
+// CreateOptions holds the query parameters accepted when creating a run.
+// RunAt (RFC 3339) or Delay (a Go duration, e.g. 24h) schedule the run to
+// start later. Timeout is a Go duration (e.g. 90s, 15m) counted from the
+// run's start time; a run that has not finished by then is failed.
+// Requests repeated with the same IdempotencyKey create a single run. Wait
+// (a Go duration, at most MaxWait) makes the request wait for the run to
+// finish.
+type CreateOptions struct {
+	Timeout        string `schema:"timeout"`
+	RunAt          string `schema:"run_at"`
+	Delay          string `schema:"delay"`
+	IdempotencyKey string `schema:"idempotency_key"`
+	Wait           string `schema:"wait"`
+}
+
+// MaxIdempotencyKeyLength caps the length of an idempotency key.
+const MaxIdempotencyKeyLength = 255
+
+// MaxWait caps how long a create request waits for its run to finish.
+const MaxWait = 5 * time.Minute
+
+// Validate checks the options for invalid values.
+func (o *CreateOptions) Validate() error {
+	ve := errors.ValidationErrs()
+
+	if o.Timeout != "" {
+		if d, err := time.ParseDuration(o.Timeout); err != nil {
+			ve.Add("timeout", "invalid duration, expected e.g. 90s or 15m")
+		} else if d <= 0 {
+			ve.Add("timeout", "must be positive")
+		}
+	}
+	if o.RunAt != "" {
+		if _, err := time.Parse(time.RFC3339, o.RunAt); err != nil {
+			ve.Add("run_at", "invalid time, expected RFC 3339 e.g. 2026-03-22T10:00:00Z")
+		}
+	}
+	if o.Delay != "" {
+		if d, err := time.ParseDuration(o.Delay); err != nil {
+			ve.Add("delay", "invalid duration, expected e.g. 90s or 24h")
+		} else if d < 0 {
+			ve.Add("delay", "cannot be negative")
+		}
+	}
+	if o.RunAt != "" && o.Delay != "" {
+		ve.Add("delay", "cannot be combined with run_at")
+	}
+	if len(o.IdempotencyKey) > MaxIdempotencyKeyLength {
+		ve.Add("idempotency_key", "cannot be longer than 255 characters")
+	}
+	if o.Wait != "" {
+		if d, err := time.ParseDuration(o.Wait); err != nil {
+			ve.Add("wait", "invalid duration, expected e.g. 30s")
+		} else if d <= 0 || d > MaxWait {
+			ve.Add("wait", "must be positive and at most 5m")
+		} else if o.RunAt != "" || o.Delay != "" {
+			ve.Add("wait", "cannot be combined with run_at or delay")
+		}
+	}
+
+	return ve.Err()
+}
+
+// TimeoutDuration returns Timeout as a duration, or zero if it is not set.
+// Call Validate first.
+func (o *CreateOptions) TimeoutDuration() time.Duration {
+	d, _ := time.ParseDuration(o.Timeout)
+	return d
+}
+
+// WaitDuration returns Wait as a duration, or zero if it is not set. Call
+// Validate first.
+func (o *CreateOptions) WaitDuration() time.Duration {
+	d, _ := time.ParseDuration(o.Wait)
+	return d
+}
+
+// StartTime returns when a run created at now should start: RunAt, or now
+// plus Delay, or now if neither is set. Call Validate first.
+func (o *CreateOptions) StartTime(now time.Time) time.Time {
+	if o.RunAt != "" {
+		t, _ := time.Parse(time.RFC3339, o.RunAt)
+		return t.UTC()
+	}
+	d, _ := time.ParseDuration(o.Delay)
+	return now.Add(d)
+}
+
+// Fingerprint identifies a create request by its flow, input and options
+// other than the idempotency key and wait, so that a key reused for a different
+// request can be told apart from a repeated one.
+func (o *CreateOptions) Fingerprint(flowName string, input map[string]any) string {
+	request := struct {
+		Flow    string         `json:"flow"`
+		Input   map[string]any `json:"input"`
+		Timeout string         `json:"timeout"`
+		RunAt   string         `json:"run_at"`
+		Delay   string         `json:"delay"`
+	}{flowName, input, o.Timeout, o.RunAt, o.Delay}
+
+	// Map keys are marshalled in sorted order, so equal requests hash equally.
+	b, _ := json.Marshal(request)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
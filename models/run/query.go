@@ -0,0 +1,75 @@
+package run
+
+import (
+	// Go Internal Packages
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+)
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListQuery holds the filters and pagination options for listing runs.
+// Date bounds are RFC3339 timestamps; Cursor is the opaque token returned
+// as NextCursor by a previous page.
+type ListQuery struct {
+	Status         Status `schema:"status"`
+	Completed      *bool  `schema:"completed"`
+	CreatedFrom    string `schema:"created_from"`
+	CreatedTo      string `schema:"created_to"`
+	Flow           string `schema:"flow"`
+	LastStepStatus *bool  `schema:"last_step_status"`
+	Cursor         string `schema:"cursor"`
+	Limit          int    `schema:"limit"`
+	Sort           string `schema:"sort"`
+}
+
+// SetDefaults fills in the page size and sort order when they are omitted.
+func (q *ListQuery) SetDefaults() {
+	if q.Limit == 0 {
+		q.Limit = DefaultListLimit
+	}
+	if q.Sort == "" {
+		q.Sort = SortDesc
+	}
+}
+
+// Validate checks the query parameters for invalid values.
+func (q *ListQuery) Validate() error {
+	ve := errors.ValidationErrs()
+
+	if q.Status != "" && !q.Status.IsValid() {
+		ve.Add("status", "must be one of SCHEDULED, PENDING, RUNNING, COMPENSATING, COMPLETED, FAILED, CANCELLED")
+	}
+	if q.CreatedFrom != "" {
+		if _, err := time.Parse(time.RFC3339, q.CreatedFrom); err != nil {
+			ve.Add("created_from", "invalid timestamp, expected RFC3339")
+		}
+	}
+	if q.CreatedTo != "" {
+		if _, err := time.Parse(time.RFC3339, q.CreatedTo); err != nil {
+			ve.Add("created_to", "invalid timestamp, expected RFC3339")
+		}
+	}
+	if q.Limit < 1 || q.Limit > MaxListLimit {
+		ve.Add("limit", "must be between 1 and 100")
+	}
+	if q.Sort != SortAsc && q.Sort != SortDesc {
+		ve.Add("sort", "must be either asc or desc")
+	}
+
+	return ve.Err()
+}
+
+// ListResult is a single page of runs. NextCursor is empty on the last page.
+type ListResult struct {
+	Runs       []Run  `json:"runs"`
+	NextCursor string `json:"next_cursor"`
+}
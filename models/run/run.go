@@ -1,13 +1,93 @@
 package run
 
+import (
+	// Go Internal Packages
+	"time"
+
+	// Local Packages
+	steprun "flowx/models/steprun"
+)
+
+// Status is the lifecycle state of a run.
+type Status string
+
+const (
+	StatusScheduled Status = "SCHEDULED" // Created and waiting for its start time
+	StatusPending   Status = "PENDING"   // Created and waiting for a worker
+	StatusRunning   Status = "RUNNING"   // Picked up by a worker
+	StatusCompleted Status = "COMPLETED" // Every step succeeded
+	StatusFailed    Status = "FAILED"    // A step failed after exhausting its retries, or the deadline passed
+	StatusCancelled Status = "CANCELLED" // Cancelled by an operator
+
+	// A step failed and the steps that completed are being compensated.
+	// The run moves on to FAILED once compensation ends.
+	StatusCompensating Status = "COMPENSATING"
+)
+
+// IsTerminal returns true if a run in this status will not be executed again.
+func (s Status) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// IsValid returns true if s is one of the known run statuses.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusScheduled, StatusPending, StatusRunning, StatusCompleted, StatusFailed, StatusCancelled, StatusCompensating:
+		return true
+	default:
+		return false
+	}
+}
+
 // Run represents a single execution instance of a flow, persisted in MongoDB.
 // Each API request creates one Run, which is then enqueued for processing.
-// On service restart, incomplete runs are re-enqueued automatically.
+// On service restart, runs that were interrupted (PENDING, RUNNING or
+// COMPENSATING) are re-enqueued automatically; runs in a terminal status are
+// left alone.
 type Run struct {
 	ID             string         `json:"_id" bson:"_id"`
+	Flow           string         `json:"flow" bson:"flow"`
 	CreatedAt      string         `json:"created_at" bson:"created_at"`
 	Input          map[string]any `json:"input" bson:"input"`
+	Status         Status         `json:"status" bson:"status"`
+	Reason         string         `json:"reason,omitempty" bson:"reason,omitempty"`
 	IsCompleted    bool           `json:"is_completed" bson:"is_completed"`
 	CompletedAt    string         `json:"completed_at" bson:"completed_at"`
 	LastStepStatus bool           `json:"last_step_status" bson:"last_step_status"`
+
+	// Output is the output of the run's last step, set once it COMPLETED.
+	Output map[string]any `json:"output,omitempty" bson:"output,omitempty"`
+
+	// IdempotencyKey, if set, is the key the run was created with, and
+	// RequestHash the fingerprint of the request that created it. Creating a
+	// run with the same key again returns this run.
+	IdempotencyKey string `json:"idempotency_key,omitempty" bson:"idempotency_key,omitempty"`
+	RequestHash    string `json:"-" bson:"request_hash,omitempty"`
+
+	// Schedule is the name of the cron schedule that created the run, if any.
+	Schedule string `json:"schedule,omitempty" bson:"schedule,omitempty"`
+
+	// RunAt, if set, is when the run was scheduled to start. The run stays
+	// SCHEDULED until then.
+	RunAt *time.Time `json:"run_at,omitempty" bson:"run_at,omitempty"`
+
+	// PendingSince is when the run last became PENDING. A run PENDING for
+	// long may never have made it into the queue, and is queued again.
+	PendingSince *time.Time `json:"-" bson:"pending_since,omitempty"`
+
+	// Deadline, if set, is when the run is failed if it has not finished.
+	Deadline string `json:"deadline,omitempty" bson:"deadline,omitempty"`
+
+	// Owner is the instance executing the run, which holds the run's lease
+	// until LeaseExpiresAt unless it renews it. Once the lease expires,
+	// another instance takes the run over.
+	Owner          string     `json:"owner,omitempty" bson:"owner,omitempty"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty" bson:"lease_expires_at,omitempty"`
+}
+
+// Details is a run together with every step run recorded for it,
+// returned by the run inspection API.
+type Details struct {
+	Run
+	Steps []steprun.StepRun `json:"steps"`
 }
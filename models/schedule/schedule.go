@@ -0,0 +1,160 @@
+package schedule
+
+import (
+	// Go Internal Packages
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+	cron "flowx/utils/cron"
+)
+
+// CatchUp decides what a schedule does about the ticks it missed, e.g.
+// while every instance was down.
+type CatchUp string
+
+const (
+	CatchUpNone   CatchUp = "none"   // Skip missed ticks, fire on the next one
+	CatchUpLatest CatchUp = "latest" // Fire once for the most recent missed tick
+	CatchUpAll    CatchUp = "all"    // Fire once for every missed tick
+)
+
+// IsValid returns true if c is one of the known catch-up policies.
+func (c CatchUp) IsValid() bool {
+	return c == CatchUpNone || c == CatchUpLatest || c == CatchUpAll
+}
+
+const (
+	SourceConfig = "config" // Defined in the config file, synced on startup
+	SourceAPI    = "api"    // Created through the API
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Definition describes a cron schedule creating runs of a flow. String
+// values in Input, at any depth, are Go templates rendered for every tick
+// with TickData, e.g. "{{.Date}}".
+type Definition struct {
+	Name    string         `json:"name" bson:"_id" koanf:"name"`
+	Flow    string         `json:"flow" bson:"flow" koanf:"flow"`
+	Cron    string         `json:"cron" bson:"cron" koanf:"cron"`
+	Input   map[string]any `json:"input,omitempty" bson:"input,omitempty" koanf:"input"`
+	CatchUp CatchUp        `json:"catch_up" bson:"catch_up" koanf:"catch_up"`
+}
+
+// SetDefaults fills in the catch-up policy when it is omitted.
+func (d *Definition) SetDefaults() {
+	if d.CatchUp == "" {
+		d.CatchUp = CatchUpLatest
+	}
+}
+
+// Validate checks the definition for invalid values. field prefixes the
+// names of the invalid fields. It does not check that the flow exists.
+func (d *Definition) Validate(ve *errors.ValidationErrorBuilder, field string) {
+	if !namePattern.MatchString(d.Name) {
+		ve.Add(field+"name", "must be non-empty and contain only letters, digits, _ and -")
+	}
+	if d.Flow == "" {
+		ve.Add(field+"flow", "is required")
+	}
+	if c, err := cron.Parse(d.Cron); err != nil {
+		ve.Add(field+"cron", err.Error())
+	} else if c.Next(time.Now()).IsZero() {
+		ve.Add(field+"cron", "never fires")
+	}
+	if !d.CatchUp.IsValid() {
+		ve.Add(field+"catch_up", "must be one of none, latest, all")
+	}
+	if _, err := RenderInput(d.Input, TickData{}); err != nil {
+		ve.Add(field+"input", err.Error())
+	}
+}
+
+// Schedule is a Definition along with its firing state, persisted in MongoDB.
+// NextRunAt is the tick the schedule fires next; instances racing to fire it
+// create the same run and only one of them advances it.
+type Schedule struct {
+	Definition `bson:",inline"`
+	Source     string     `json:"source" bson:"source"`
+	Paused     bool       `json:"paused" bson:"paused"`
+	NextRunAt  time.Time  `json:"next_run_at" bson:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"` // Tick of the last run created
+	LastRunID  string     `json:"last_run_id,omitempty" bson:"last_run_id,omitempty"`
+	CreatedAt  string     `json:"created_at" bson:"created_at"`
+}
+
+// TickData is available to the templates in a schedule's input.
+type TickData struct {
+	Schedule    string // Name of the schedule
+	ScheduledAt string // The tick, RFC 3339
+	Date        string // The tick's date, e.g. 2026-03-22
+}
+
+// NewTickData returns the template data for the given tick of a schedule.
+func NewTickData(name string, tick time.Time) TickData {
+	return TickData{
+		Schedule:    name,
+		ScheduledAt: tick.UTC().Format(time.RFC3339),
+		Date:        tick.UTC().Format(time.DateOnly),
+	}
+}
+
+// RenderInput returns a copy of input with every string value containing
+// a template action rendered with data.
+func RenderInput(input map[string]any, data TickData) (map[string]any, error) {
+	rendered, err := render(input, data)
+	if err != nil {
+		return nil, err
+	}
+	out, _ := rendered.(map[string]any)
+	return out, nil
+}
+
+func render(v any, data TickData) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if v == nil {
+			return v, nil
+		}
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			r, err := render(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			r, err := render(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		tmpl, err := template.New("input").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", v, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("cannot render template %q: %w", v, err)
+		}
+		return buf.String(), nil
+	default:
+		return v, nil
+	}
+}
@@ -1,5 +1,48 @@
 package steprun
 
+// End states recorded on a step run once it stops executing.
+const (
+	StateCompleted = "COMPLETED"
+	StateFailed    = "FAILED"
+	StateCancelled = "CANCELLED" // Interrupted because its run was cancelled
+	StateSkipped   = "SKIPPED"   // Not executed, see the skip reasons below
+)
+
+// Reasons recorded on SKIPPED step runs.
+const (
+	ReasonConditionNotMet = "condition not met" // The step's When returned false; its input is passed through
+	ReasonBranchNotTaken  = "branch not taken"  // No branch leading to the step was chosen
+)
+
+// Classifications prefixed to the reason of FAILED step runs, as in
+// "PERMANENT: missing required field: name".
+const (
+	FailurePermanent        = "PERMANENT"         // The step returned a permanent error and was not retried
+	FailureRetriesExhausted = "RETRIES_EXHAUSTED" // Every attempt allowed by the retry policy failed
+	FailureMaxElapsedTime   = "MAX_ELAPSED_TIME"  // Retrying stopped at the retry policy's max elapsed time
+	FailureTimedOut         = "TIMED_OUT"         // The last attempt overran the step's timeout
+)
+
+// FailureReason formats the reason recorded on a FAILED step run.
+func FailureReason(classification string, err error) string {
+	return classification + ": " + err.Error()
+}
+
+// States recorded on a step run once its Compensate hook has run.
+const (
+	CompensationCompleted = "COMPENSATED"
+	CompensationFailed    = "FAILED"
+)
+
+// CompensationState captures the outcome of undoing a completed step after
+// its run failed.
+type CompensationState struct {
+	State    string `json:"state" bson:"state"` // COMPENSATED or FAILED
+	Reason   string `json:"reason,omitempty" bson:"reason,omitempty"`
+	EndedAt  string `json:"ended_at" bson:"ended_at"`
+	Duration int    `json:"duration" bson:"duration"`
+}
+
 // StepRunID is the composite key for a step run document.
 // A step run is uniquely identified by its parent run and step name.
 type StepRunID struct {
@@ -9,25 +52,69 @@ type StepRunID struct {
 
 // StepEndState captures the final state of a step after execution.
 type StepEndState struct {
-	EndState string         `json:"end_state" bson:"end_state"` // COMPLETED or FAILED
+	EndState string         `json:"end_state" bson:"end_state"` // COMPLETED, FAILED, CANCELLED or SKIPPED
 	Reason   string         `json:"reason" bson:"reason"`
 	EndedAt  string         `json:"ended_at" bson:"ended_at"`
 	Output   map[string]any `json:"output" bson:"output"`
 	Duration int            `json:"duration" bson:"duration"`
 }
 
+// Attempt is a single try at executing a step. Error is empty for the attempt
+// that succeeded, and Backoff is the wait chosen before the next attempt,
+// empty if the step was not retried after it.
+type Attempt struct {
+	Number    int    `json:"number" bson:"number"`
+	StartedAt string `json:"started_at" bson:"started_at"`
+	EndedAt   string `json:"ended_at" bson:"ended_at"`
+	Duration  int    `json:"duration" bson:"duration"`
+	Error     string `json:"error,omitempty" bson:"error,omitempty"`
+	Backoff   string `json:"backoff,omitempty" bson:"backoff,omitempty"`
+}
+
+// PreviousExecution is an earlier execution of a step that was executed
+// again by a retry or rerun of its run. It is kept so that the run
+// timeline still shows what happened the first time around.
+type PreviousExecution struct {
+	CreatedAt string         `json:"created_at" bson:"created_at"`
+	Input     map[string]any `json:"input" bson:"input"`
+	Ending    *StepEndState  `json:"ending,omitempty" bson:"ending,omitempty"`
+	Attempts  []Attempt      `json:"attempts,omitempty" bson:"attempts,omitempty"`
+
+	Compensation *CompensationState `json:"compensation,omitempty" bson:"compensation,omitempty"`
+}
+
 // StepRun tracks the execution state of a single step within a run.
 // It is persisted in MongoDB so that on restart, the service can
-// determine which step to resume from.
+// determine which step to resume from. Version counts the attempts made
+// by the current execution, each of which is kept in Attempts.
 type StepRun struct {
 	ID        StepRunID      `json:"_id" bson:"_id"`
 	Version   int            `json:"version" bson:"version"`
 	CreatedAt string         `json:"created_at" bson:"created_at"`
 	Input     map[string]any `json:"input" bson:"input"`
 	Ending    *StepEndState  `json:"ending,omitempty" bson:"ending,omitempty"`
+	Attempts  []Attempt      `json:"attempts,omitempty" bson:"attempts,omitempty"`
+
+	// Compensation is set once the step's Compensate hook has run after
+	// its run failed.
+	Compensation *CompensationState `json:"compensation,omitempty" bson:"compensation,omitempty"`
+
+	// History holds previous executions, oldest first.
+	History []PreviousExecution `json:"history,omitempty" bson:"history,omitempty"`
 }
 
 // IsEndedSuccessfully returns true if the step completed without errors.
 func (s *StepRun) IsEndedSuccessfully() bool {
-	return s.Ending != nil && s.Ending.EndState == "COMPLETED"
+	return s.Ending != nil && s.Ending.EndState == StateCompleted
+}
+
+// IsCompensated returns true if the step's effects were undone after its
+// run failed.
+func (s *StepRun) IsCompensated() bool {
+	return s.Compensation != nil && s.Compensation.State == CompensationCompleted
+}
+
+// IsSkipped returns true if the step was deliberately not executed.
+func (s *StepRun) IsSkipped() bool {
+	return s.Ending != nil && s.Ending.EndState == StateSkipped
 }
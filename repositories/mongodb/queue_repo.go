@@ -0,0 +1,181 @@
+package mongodb
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+	models "flowx/models/queue"
+
+	// External Packages
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RunQueue is a durable queue of runs backed by the "run_queue" collection.
+// Claiming a run hides it from other workers for the visibility timeout
+// instead of removing it, so a run claimed by a worker that dies is delivered
+// again once the timeout passes. Acking the claim removes the run.
+type RunQueue struct {
+	collection   *mongo.Collection
+	visibility   time.Duration
+	pollInterval time.Duration
+
+	// wake lets Push hand a new run to a worker of this instance waiting in
+	// Pop without it having to wait for the next poll.
+	wake chan struct{}
+}
+
+// NewRunQueue creates a RunQueue backed by the "run_queue" collection.
+func NewRunQueue(client *mongo.Client, visibility, pollInterval time.Duration) *RunQueue {
+	return &RunQueue{
+		collection:   client.Database("flowx").Collection("run_queue"),
+		visibility:   visibility,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// CreateIndexes creates the index used to find the next visible run.
+func (q *RunQueue) CreateIndexes(ctx context.Context) error {
+	index := mongo.IndexModel{Keys: bson.D{{Key: "visible_at", Value: 1}, {Key: "enqueued_at", Value: 1}}}
+	_, err := q.collection.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// Push adds a run to the queue. Pushing a run that is already queued does
+// not queue it twice, and in particular does not disturb a worker's claim on
+// it. If the run is claimed, it is marked as requeued instead: the run may
+// have been reopened after the worker processed it, so acking the claim puts
+// the run back into the queue rather than removing it.
+func (q *RunQueue) Push(ctx context.Context, runID string) error {
+	now := time.Now().UTC()
+	update := bson.A{bson.M{"$set": bson.M{
+		"enqueued_at": bson.M{"$ifNull": bson.A{"$enqueued_at", now}},
+		"visible_at":  bson.M{"$ifNull": bson.A{"$visible_at", now}},
+		"deliveries":  bson.M{"$ifNull": bson.A{"$deliveries", 0}},
+		"requeued": bson.M{"$or": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$receipt"}, "string"}},
+			bson.M{"$ifNull": bson.A{"$requeued", false}},
+		}},
+	}}}
+	opts := options.UpdateOne().SetUpsert(true)
+	if _, err := q.collection.UpdateOne(ctx, bson.M{"_id": runID}, update, opts); err != nil {
+		return err
+	}
+
+	q.notify()
+	return nil
+}
+
+// notify wakes up a worker of this instance waiting in Pop, if there is one.
+func (q *RunQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pop claims the oldest visible run, blocking until one is available or ctx
+// is done.
+func (q *RunQueue) Pop(ctx context.Context) (*models.Item, error) {
+	for {
+		item, err := q.claim(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			return item, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-q.wake:
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+// claim atomically claims the oldest visible run, or returns nil if none is.
+func (q *RunQueue) claim(ctx context.Context) (*models.Item, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"visible_at": bson.M{"$lte": now}}
+	update := bson.M{
+		"$set": bson.M{"visible_at": now.Add(q.visibility), "receipt": uuid.New().String()},
+		"$inc": bson.M{"deliveries": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "enqueued_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var item models.Item
+	err := q.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Extend keeps a claimed run hidden for another visibility timeout. It fails
+// if the claim was lost, i.e. the run was delivered to another worker.
+func (q *RunQueue) Extend(ctx context.Context, item *models.Item) error {
+	filter := bson.M{"_id": item.RunID, "receipt": item.Receipt}
+	update := bson.M{"$set": bson.M{"visible_at": time.Now().UTC().Add(q.visibility)}}
+
+	res, err := q.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("claim on run %s was lost", item.RunID)
+	}
+	return nil
+}
+
+// Ack removes a claimed run from the queue once it has been processed. A run
+// pushed again while claimed is made visible again instead, see Push.
+func (q *RunQueue) Ack(ctx context.Context, item *models.Item) error {
+	filter := bson.M{"_id": item.RunID, "receipt": item.Receipt, "requeued": bson.M{"$ne": true}}
+	res, err := q.collection.DeleteOne(ctx, filter)
+	if err != nil || res.DeletedCount > 0 {
+		return err
+	}
+
+	// Nothing was deleted: either the run was pushed again while claimed, or
+	// the claim was lost to another worker and nothing matches here either.
+	filter = bson.M{"_id": item.RunID, "receipt": item.Receipt, "requeued": true}
+	update := bson.M{
+		"$set":   bson.M{"visible_at": time.Now().UTC(), "requeued": false},
+		"$unset": bson.M{"receipt": ""},
+	}
+	requeued, err := q.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if requeued.MatchedCount > 0 {
+		q.notify()
+	}
+	return nil
+}
+
+// Release gives up the claim on a run that was not processed, making it
+// visible to every worker again right away.
+func (q *RunQueue) Release(ctx context.Context, item *models.Item) error {
+	filter := bson.M{"_id": item.RunID, "receipt": item.Receipt}
+	update := bson.M{
+		"$set":   bson.M{"visible_at": time.Now().UTC(), "requeued": false},
+		"$unset": bson.M{"receipt": ""},
+	}
+
+	_, err := q.collection.UpdateOne(ctx, filter, update)
+	return err
+}
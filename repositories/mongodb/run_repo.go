@@ -3,14 +3,19 @@ package mongodb
 import (
 	// Go Internal Packages
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
 
 	// Local Packages
+	errors "flowx/errors"
 	models "flowx/models/run"
 	helpers "flowx/utils/helpers"
 
 	// External Packages
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // RunRepository handles all MongoDB operations for the "runs" collection.
@@ -25,16 +30,41 @@ func NewRunRepository(client *mongo.Client) *RunRepository {
 	}
 }
 
-// Create inserts a new run document into MongoDB.
+// Create inserts a new run document into MongoDB. It returns Conflict if a
+// run with the same ID or idempotency key exists.
 func (r *RunRepository) Create(ctx context.Context, run models.Run) error {
 	_, err := r.collection.InsertOne(ctx, run)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.E(errors.Conflict, "run already exists")
+	}
+	return err
+}
+
+// CreateIndexes creates the indexes used to find the scheduled runs that are
+// due and the runs left PENDING, and the unique index on idempotency keys.
+func (r *RunRepository) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "pending_since", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "idempotency_key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+	}
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
 
-// GetIncomplete returns all runs that have not yet finished executing.
-// These are re-enqueued on service startup for recovery.
+// GetIncomplete returns all runs that were interrupted before reaching a
+// terminal status. These are re-enqueued on service startup for recovery.
+// Runs created before the status field existed are matched on is_completed.
 func (r *RunRepository) GetIncomplete(ctx context.Context) ([]models.Run, error) {
-	filter := bson.M{"is_completed": false}
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": bson.M{"$in": bson.A{models.StatusPending, models.StatusRunning, models.StatusCompensating}}},
+			bson.M{"status": bson.M{"$exists": false}, "is_completed": false},
+		},
+	}
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -48,18 +78,367 @@ func (r *RunRepository) GetIncomplete(ctx context.Context) ([]models.Run, error)
 	return results, nil
 }
 
-// MarkComplete updates a run as completed with a timestamp and success status.
-func (r *RunRepository) MarkComplete(ctx context.Context, runID string) error {
-	curTime := helpers.GetCurrentDateTime()
-	update := bson.M{
-		"$set": bson.M{
-			"is_completed":     true,
-			"completed_at":     curTime,
-			"last_step_status": true,
+// AcquireLease makes owner the holder of a run's lease until ttl from now.
+// It succeeds if the run is not terminal and its lease is free or expired,
+// and returns false otherwise, including when owner already holds it, so a
+// run queued twice is never executed twice by the same instance.
+func (r *RunRepository) AcquireLease(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	filter := bson.M{
+		"_id":    runID,
+		"status": bson.M{"$nin": terminalStatuses},
+		"$or": bson.A{
+			bson.M{"owner": bson.M{"$exists": false}},
+			bson.M{"lease_expires_at": bson.M{"$lte": now}},
 		},
 	}
+	update := bson.M{"$set": bson.M{"owner": owner, "lease_expires_at": now.Add(ttl)}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// RenewLease extends owner's lease on a run until ttl from now. It returns
+// false if owner no longer holds the lease, or the run already reached a
+// terminal status, e.g. it was cancelled through another instance.
+func (r *RunRepository) RenewLease(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error) {
+	filter := bson.M{"_id": runID, "owner": owner, "status": bson.M{"$nin": terminalStatuses}}
+	update := bson.M{"$set": bson.M{"lease_expires_at": time.Now().UTC().Add(ttl)}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// ReleaseLease gives up owner's lease on a run, if owner still holds it.
+func (r *RunRepository) ReleaseLease(ctx context.Context, runID, owner string) error {
+	filter := bson.M{"_id": runID, "owner": owner}
+	update := bson.M{"$unset": bson.M{"owner": "", "lease_expires_at": ""}}
 
-	filter := bson.M{"_id": runID}
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// GetOrphaned returns the RUNNING and COMPENSATING runs that nobody is
+// executing: their lease expired, or was released without the run finishing
+// (e.g. on shutdown).
+func (r *RunRepository) GetOrphaned(ctx context.Context) ([]models.Run, error) {
+	filter := bson.M{
+		"status": bson.M{"$in": bson.A{models.StatusRunning, models.StatusCompensating}},
+		"$or": bson.A{
+			bson.M{"owner": bson.M{"$exists": false}},
+			bson.M{"lease_expires_at": bson.M{"$lte": time.Now().UTC()}},
+		},
+	}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Run
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetDue returns up to limit SCHEDULED runs whose start time is not after
+// now, the earliest first.
+func (r *RunRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]models.Run, error) {
+	filter := bson.M{"status": models.StatusScheduled, "run_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "run_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Run
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetStalePending returns the runs that have been PENDING since before the
+// given time, and restarts their wait so that each is returned at most once
+// per wait. Such runs may never have made it into the queue.
+func (r *RunRepository) GetStalePending(ctx context.Context, before time.Time) ([]models.Run, error) {
+	filter := bson.M{"status": models.StatusPending, "pending_since": bson.M{"$lte": before}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Run
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	ids := make(bson.A, len(results))
+	for i, run := range results {
+		ids[i] = run.ID
+	}
+	filter = bson.M{"_id": bson.M{"$in": ids}, "status": models.StatusPending}
+	update := bson.M{"$set": bson.M{"pending_since": time.Now().UTC()}}
+	if _, err := r.collection.UpdateMany(ctx, filter, update); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Promote moves a SCHEDULED run into PENDING once it has been queued. It
+// returns false if the run is no longer SCHEDULED, e.g. it was cancelled or
+// already picked up by a worker.
+func (r *RunRepository) Promote(ctx context.Context, runID string) (bool, error) {
+	filter := bson.M{"_id": runID, "status": models.StatusScheduled}
+	update := bson.M{"$set": bson.M{"status": models.StatusPending, "pending_since": time.Now().UTC()}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MarkRunning updates a run's status once a worker picks it up. It returns
+// false if the run is no longer runnable, e.g. it was cancelled while queued.
+func (r *RunRepository) MarkRunning(ctx context.Context, runID string) (bool, error) {
+	filter := bson.M{"_id": runID, "status": bson.M{"$nin": settledStatuses}}
+	update := bson.M{"$set": bson.M{"status": models.StatusRunning}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MarkCompensating moves a RUNNING run whose execution failed into
+// COMPENSATING, recording why it failed. It returns false if the run is no
+// longer RUNNING, e.g. it was cancelled meanwhile.
+func (r *RunRepository) MarkCompensating(ctx context.Context, runID, reason string) (bool, error) {
+	filter := bson.M{"_id": runID, "status": models.StatusRunning}
+	update := bson.M{"$set": bson.M{"status": models.StatusCompensating, "reason": reason}}
+
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// MarkComplete updates a run as completed with a timestamp and success
+// status, recording its output. It returns false if the run had already
+// reached a terminal status, e.g. it was cancelled meanwhile.
+func (r *RunRepository) MarkComplete(ctx context.Context, runID string, output map[string]any) (bool, error) {
+	return r.finish(ctx, runID, models.StatusCompleted, true, "", output)
+}
+
+// MarkFailed updates a run as failed, recording why its last step failed.
+// It returns false if the run had already reached a terminal status.
+func (r *RunRepository) MarkFailed(ctx context.Context, runID, reason string) (bool, error) {
+	return r.finish(ctx, runID, models.StatusFailed, false, reason, nil)
+}
+
+// MarkCancelled updates a run as cancelled. It returns false if the run had
+// already reached a terminal status or is being compensated.
+func (r *RunRepository) MarkCancelled(ctx context.Context, runID, reason string) (bool, error) {
+	return r.finishFrom(ctx, runID, settledStatuses, models.StatusCancelled, false, reason, nil)
+}
+
+// Reopen moves a run in one of the given statuses back to PENDING so that it
+// can be executed again. It returns false if the run is in any other status.
+func (r *RunRepository) Reopen(ctx context.Context, runID string, from []models.Status) (bool, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"status":           models.StatusPending,
+			"reason":           "",
+			"is_completed":     false,
+			"completed_at":     helpers.GetNotEndedTime(),
+			"last_step_status": false,
+			"pending_since":    time.Now().UTC(),
+		},
+		"$unset": bson.M{"deadline": "", "output": ""},
+	}
+
+	filter := bson.M{"_id": runID, "status": bson.M{"$in": from}}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// terminalStatuses lists the statuses a run never leaves on its own.
+var terminalStatuses = bson.A{models.StatusCompleted, models.StatusFailed, models.StatusCancelled}
+
+// settledStatuses lists the statuses a run can no longer be started or
+// cancelled from: the terminal ones and COMPENSATING, which always ends
+// in FAILED.
+var settledStatuses = append(bson.A{models.StatusCompensating}, terminalStatuses...)
+
+// finish moves a run into a terminal status. is_completed is set for every
+// terminal status so that the run is never picked up by recovery again.
+// Runs that are already terminal are left untouched and false is returned,
+// so a late completion can never overwrite a cancellation (or vice versa).
+// Only completed runs have an output.
+func (r *RunRepository) finish(ctx context.Context, runID string, status models.Status, lastStepStatus bool, reason string, output map[string]any) (bool, error) {
+	return r.finishFrom(ctx, runID, terminalStatuses, status, lastStepStatus, reason, output)
+}
+
+// finishFrom is finish for runs in any status except the excluded ones.
+func (r *RunRepository) finishFrom(ctx context.Context, runID string, excluded bson.A, status models.Status, lastStepStatus bool, reason string, output map[string]any) (bool, error) {
+	curTime := helpers.GetCurrentDateTime()
+	set := bson.M{
+		"status":           status,
+		"reason":           reason,
+		"is_completed":     true,
+		"completed_at":     curTime,
+		"last_step_status": lastStepStatus,
+	}
+	update := bson.M{"$set": set}
+	if output != nil {
+		set["output"] = output
+	} else {
+		update["$unset"] = bson.M{"output": ""}
+	}
+
+	filter := bson.M{"_id": runID, "status": bson.M{"$nin": excluded}}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Get returns the run with the given ID, or a NotFound error if it does not exist.
+func (r *RunRepository) Get(ctx context.Context, runID string) (*models.Run, error) {
+	var run models.Run
+	err := r.collection.FindOne(ctx, bson.M{"_id": runID}).Decode(&run)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.E(errors.NotFound, "run not found")
+		}
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// GetByIdempotencyKey returns the run created with the given idempotency key.
+func (r *RunRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Run, error) {
+	var run models.Run
+	err := r.collection.FindOne(ctx, bson.M{"idempotency_key": key}).Decode(&run)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.E(errors.NotFound, "run not found")
+		}
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// listCursor is the position of the last run on a page. It is serialized
+// into the opaque next-cursor token handed back to clients.
+type listCursor struct {
+	CreatedAt string `json:"c"`
+	ID        string `json:"i"`
+}
+
+func encodeCursor(run models.Run) string {
+	raw, _ := json.Marshal(listCursor{CreatedAt: run.CreatedAt, ID: run.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(token string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.E(errors.Invalid, "invalid cursor")
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return nil, errors.E(errors.Invalid, "invalid cursor")
+	}
+	return &c, nil
+}
+
+// List returns a page of runs matching the query, ordered by created_at
+// (ties broken by _id) in the requested direction. The returned cursor
+// points at the last run of the page and is empty when no more runs remain.
+func (r *RunRepository) List(ctx context.Context, query models.ListQuery) ([]models.Run, string, error) {
+	filter := bson.M{}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if query.Completed != nil {
+		filter["is_completed"] = *query.Completed
+	}
+	if query.Flow != "" {
+		filter["flow"] = query.Flow
+	}
+	if query.LastStepStatus != nil {
+		filter["last_step_status"] = *query.LastStepStatus
+	}
+
+	createdAt := bson.M{}
+	if query.CreatedFrom != "" {
+		from, _ := time.Parse(time.RFC3339, query.CreatedFrom)
+		createdAt["$gte"] = helpers.FormatDateTime(from)
+	}
+	if query.CreatedTo != "" {
+		to, _ := time.Parse(time.RFC3339, query.CreatedTo)
+		createdAt["$lte"] = helpers.FormatDateTime(to)
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	direction, op := -1, "$lt"
+	if query.Sort == models.SortAsc {
+		direction, op = 1, "$gt"
+	}
+
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{op: c.CreatedAt}},
+			bson.M{"created_at": c.CreatedAt, "_id": bson.M{op: c.ID}},
+		}
+	}
+
+	// Fetch one extra document to find out whether another page exists.
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(query.Limit + 1))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	results := []models.Run{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, "", err
+	}
+
+	if len(results) <= query.Limit {
+		return results, "", nil
+	}
+
+	results = results[:query.Limit]
+	return results, encodeCursor(results[len(results)-1]), nil
+}
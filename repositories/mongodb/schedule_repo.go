@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"time"
+
+	// Local Packages
+	errors "flowx/errors"
+	models "flowx/models/schedule"
+
+	// External Packages
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ScheduleRepository handles all MongoDB operations for the "schedules" collection.
+type ScheduleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewScheduleRepository creates a new ScheduleRepository backed by the "schedules" collection.
+func NewScheduleRepository(client *mongo.Client) *ScheduleRepository {
+	return &ScheduleRepository{
+		collection: client.Database("flowx").Collection("schedules"),
+	}
+}
+
+// CreateIndexes creates the index used to find the schedules that are due.
+func (r *ScheduleRepository) CreateIndexes(ctx context.Context) error {
+	index := mongo.IndexModel{Keys: bson.D{{Key: "paused", Value: 1}, {Key: "next_run_at", Value: 1}}}
+	_, err := r.collection.Indexes().CreateOne(ctx, index)
+	return err
+}
+
+// Create inserts a new schedule. It returns Conflict if a schedule with the
+// same name exists.
+func (r *ScheduleRepository) Create(ctx context.Context, schedule models.Schedule) error {
+	_, err := r.collection.InsertOne(ctx, schedule)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.E(errors.Conflict, fmt.Sprintf("schedule %s already exists", schedule.Name))
+	}
+	return err
+}
+
+// Sync stores a schedule defined in the config file. An existing schedule
+// gets the new definition but keeps its firing state, unless its cron
+// expression changed, in which case it fires next at nextRunAt.
+func (r *ScheduleRepository) Sync(ctx context.Context, def models.Definition, nextRunAt time.Time, createdAt string) error {
+	definition := bson.M{
+		"flow":     def.Flow,
+		"cron":     def.Cron,
+		"input":    def.Input,
+		"catch_up": def.CatchUp,
+		"source":   models.SourceConfig,
+	}
+
+	// Update the definition of a schedule whose cron expression is unchanged.
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": def.Name, "cron": def.Cron}, bson.M{"$set": definition})
+	if err != nil || res.MatchedCount > 0 {
+		return err
+	}
+
+	definition["next_run_at"] = nextRunAt
+	update := bson.M{
+		"$set":         definition,
+		"$setOnInsert": bson.M{"paused": false, "created_at": createdAt},
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": def.Name}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// Prune deletes the schedules defined in the config file whose names are
+// not in keep, i.e. that were removed from it.
+func (r *ScheduleRepository) Prune(ctx context.Context, keep []string) error {
+	filter := bson.M{"source": models.SourceConfig, "_id": bson.M{"$nin": keep}}
+	_, err := r.collection.DeleteMany(ctx, filter)
+	return err
+}
+
+// Get returns the schedule with the given name.
+func (r *ScheduleRepository) Get(ctx context.Context, name string) (*models.Schedule, error) {
+	var schedule models.Schedule
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&schedule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.E(errors.NotFound, "schedule not found")
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// List returns every schedule ordered by name.
+func (r *ScheduleRepository) List(ctx context.Context) ([]models.Schedule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []models.Schedule{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Delete removes a schedule. Runs it already created are kept.
+func (r *ScheduleRepository) Delete(ctx context.Context, name string) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return errors.E(errors.NotFound, "schedule not found")
+	}
+	return nil
+}
+
+// Pause stops a schedule from firing.
+func (r *ScheduleRepository) Pause(ctx context.Context, name string) error {
+	return r.update(ctx, name, bson.M{"paused": true})
+}
+
+// Resume lets a paused schedule fire again, next at nextRunAt, so that the
+// ticks missed while it was paused are not caught up.
+func (r *ScheduleRepository) Resume(ctx context.Context, name string, nextRunAt time.Time) error {
+	return r.update(ctx, name, bson.M{"paused": false, "next_run_at": nextRunAt})
+}
+
+func (r *ScheduleRepository) update(ctx context.Context, name string, set bson.M) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": name}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return errors.E(errors.NotFound, "schedule not found")
+	}
+	return nil
+}
+
+// GetDue returns the schedules that are not paused and whose next tick is
+// not after now.
+func (r *ScheduleRepository) GetDue(ctx context.Context, now time.Time) ([]models.Schedule, error) {
+	filter := bson.M{"paused": false, "next_run_at": bson.M{"$lte": now}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []models.Schedule
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Advance moves a schedule's next tick from `from` to `next`, recording the
+// run created for the tick `ranAt`, if any. It returns false if the schedule
+// was advanced or paused meanwhile, e.g. by another instance.
+func (r *ScheduleRepository) Advance(ctx context.Context, name string, from, next time.Time, ranAt *time.Time, runID string) (bool, error) {
+	filter := bson.M{"_id": name, "paused": false, "next_run_at": from}
+	set := bson.M{"next_run_at": next}
+	if ranAt != nil {
+		set["last_run_at"] = *ranAt
+		set["last_run_id"] = runID
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -29,16 +29,25 @@ func NewStepRunRepository(client *mongo.Client) *StepRunRepository {
 }
 
 // RecordStepStart upserts a step run document when execution begins.
-// Uses upsert to handle idempotent restarts safely.
+// Uses upsert to handle idempotent restarts safely. If the step had already
+// finished once (the run is being retried or rerun), that execution is moved
+// into the step run's history instead of being overwritten (along with its
+// compensation, if any). Attempts of an execution that never finished are
+// discarded.
 func (r *StepRunRepository) RecordStepStart(ctx context.Context, runID, stepName string, input map[string]any) error {
 	stepID := models.StepRunID{
 		RunID:    runID,
 		StepName: stepName,
 	}
 
+	previous, err := r.finishedExecution(ctx, stepID)
+	if err != nil {
+		return err
+	}
+
 	curTime := helpers.GetCurrentDateTime()
 	stepRun := models.StepRun{
-		Version:   1,
+		Version:   0,
 		ID:        stepID,
 		CreatedAt: curTime,
 		Input:     input,
@@ -46,7 +55,10 @@ func (r *StepRunRepository) RecordStepStart(ctx context.Context, runID, stepName
 	}
 
 	filter := bson.M{"_id": stepID}
-	update := bson.M{"$set": stepRun}
+	update := bson.M{"$set": stepRun, "$unset": bson.M{"ending": "", "attempts": "", "compensation": ""}}
+	if previous != nil {
+		update["$push"] = bson.M{"history": previous}
+	}
 	opts := options.UpdateOne().SetUpsert(true)
 
 	res, err := r.collection.UpdateOne(ctx, filter, update, opts)
@@ -63,6 +75,80 @@ func (r *StepRunRepository) RecordStepStart(ctx context.Context, runID, stepName
 	return nil
 }
 
+// Reset archives the current execution of a step into its history and
+// clears its ending, so the executor treats the step as pending again.
+// Used to rerun a run from an arbitrary step.
+func (r *StepRunRepository) Reset(ctx context.Context, runID, stepName string) error {
+	stepID := models.StepRunID{
+		RunID:    runID,
+		StepName: stepName,
+	}
+
+	previous, err := r.finishedExecution(ctx, stepID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return errors.E(errors.Invalid, fmt.Sprintf("step %s has not finished for this run", stepName))
+	}
+
+	update := bson.M{
+		"$set":   bson.M{"version": 0},
+		"$unset": bson.M{"ending": "", "attempts": "", "compensation": ""},
+		"$push":  bson.M{"history": previous},
+	}
+
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": stepID}, update)
+	return err
+}
+
+// finishedExecution returns the current execution of a step run for archiving,
+// or nil if the step has never been recorded or has not finished yet.
+func (r *StepRunRepository) finishedExecution(ctx context.Context, stepID models.StepRunID) (*models.PreviousExecution, error) {
+	var existing models.StepRun
+	err := r.collection.FindOne(ctx, bson.M{"_id": stepID}).Decode(&existing)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if existing.Ending == nil {
+		return nil, nil
+	}
+	return &models.PreviousExecution{
+		CreatedAt: existing.CreatedAt,
+		Input:     existing.Input,
+		Ending:    existing.Ending,
+		Attempts:  existing.Attempts,
+
+		Compensation: existing.Compensation,
+	}, nil
+}
+
+// RecordAttempt appends a finished attempt to a step run and bumps its version.
+func (r *StepRunRepository) RecordAttempt(ctx context.Context, runID, stepName string, attempt models.Attempt) error {
+	stepID := models.StepRunID{
+		RunID:    runID,
+		StepName: stepName,
+	}
+
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$inc":  bson.M{"version": 1},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": stepID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("step run not found")
+	}
+	return nil
+}
+
 // RecordStepEnd updates a step run with its final execution state (COMPLETED or FAILED).
 func (r *StepRunRepository) RecordStepEnd(ctx context.Context, runID, stepName, state, reason string, duration int, output map[string]any) error {
 	stepID := models.StepRunID{
@@ -94,20 +180,49 @@ func (r *StepRunRepository) RecordStepEnd(ctx context.Context, runID, stepName,
 	return nil
 }
 
-// GetLastRecordedStep returns the most recently created step run for a given run.
-// Returns nil if no steps have been recorded yet (fresh run).
-func (r *StepRunRepository) GetLastRecordedStep(ctx context.Context, runID string) (*models.StepRun, error) {
+// RecordCompensation records the outcome of a step's Compensate hook.
+func (r *StepRunRepository) RecordCompensation(ctx context.Context, runID, stepName, state, reason string, duration int) error {
+	stepID := models.StepRunID{
+		RunID:    runID,
+		StepName: stepName,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"compensation": models.CompensationState{
+				State:    state,
+				Reason:   reason,
+				EndedAt:  helpers.GetCurrentDateTime(),
+				Duration: duration,
+			},
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": stepID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("step run not found")
+	}
+	return nil
+}
+
+// GetByRunID returns every step run recorded for a given run, in the order
+// the steps last started.
+func (r *StepRunRepository) GetByRunID(ctx context.Context, runID string) ([]models.StepRun, error) {
 	filter := bson.M{"_id.run_id": runID}
-	opts := options.FindOne().SetSort(bson.M{"created_at": -1})
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
 
-	var stepRun models.StepRun
-	err := r.collection.FindOne(ctx, filter, opts).Decode(&stepRun)
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, nil
-		}
 		return nil, err
 	}
 
-	return &stepRun, nil
+	results := []models.StepRun{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
 }
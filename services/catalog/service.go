@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	// Local Packages
+	config "flowx/config"
+	flow "flowx/flow"
+	helpers "flowx/utils/helpers"
+)
+
+// RetryInfo describes the retry behaviour applied to a step. MaxAttempts
+// counts the first attempt.
+type RetryInfo struct {
+	MaxAttempts    int     `json:"max_attempts"`
+	InitialBackoff string  `json:"initial_backoff"`
+	MaxBackoff     string  `json:"max_backoff"`
+	BackoffFactor  float64 `json:"backoff_factor"`
+	JitterFraction float64 `json:"jitter_fraction"`
+	MaxElapsedTime string  `json:"max_elapsed_time,omitempty"`
+}
+
+// StepInfo describes a single step of a flow. DependsOn lists the steps it
+// waits for, with implicit ordering and groups resolved. Conditional and
+// Branching tell whether the step has a When or Branch hook, and Compensable
+// whether it has a Compensate hook. For a parallel group, Parallel lists the
+// members that execute concurrently.
+type StepInfo struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	DependsOn   []string   `json:"depends_on,omitempty"`
+	Conditional bool       `json:"conditional,omitempty"`
+	Branching   bool       `json:"branching,omitempty"`
+	Compensable bool       `json:"compensable,omitempty"`
+	Retry       *RetryInfo `json:"retry,omitempty"`
+	Timeout     string     `json:"timeout,omitempty"`
+	Parallel    []StepInfo `json:"parallel,omitempty"`
+}
+
+// FlowInfo describes a registered flow. Name is the registry name used in
+// URLs and on runs; DisplayName is the name declared on the flow itself.
+type FlowInfo struct {
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name"`
+	Steps       []StepInfo `json:"steps"`
+}
+
+// CatalogService exposes the registered flows, whether declared in code or
+// loaded from definition files, so that clients can discover them (and
+// render their pipelines) before any run exists.
+type CatalogService struct {
+	config config.Executor
+}
+
+// NewService creates a CatalogService. The executor config supplies the
+// retry settings for steps that do not declare their own.
+func NewService(config config.Executor) *CatalogService {
+	return &CatalogService{config: config}
+}
+
+// List returns every registered flow, ordered by name.
+func (c *CatalogService) List() []FlowInfo {
+	names := flow.Names()
+	flows := make([]FlowInfo, 0, len(names))
+	for _, name := range names {
+		f, _ := flow.Get(name)
+		flows = append(flows, c.describe(name, f))
+	}
+	return flows
+}
+
+// Get returns the flow registered under name, or a NotFound error.
+func (c *CatalogService) Get(name string) (*FlowInfo, error) {
+	f, err := flow.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info := c.describe(name, f)
+	return &info, nil
+}
+
+// describe builds the FlowInfo for a flow, listing its steps in order.
+func (c *CatalogService) describe(name string, f flow.Flow) FlowInfo {
+	parents := make(map[string][]string)
+	for _, node := range f.Nodes() {
+		parents[node.Name] = node.Parents
+	}
+
+	steps := make([]StepInfo, 0, len(f.Steps))
+	for _, step := range f.GetAllSteps() {
+		steps = append(steps, c.describeStep(step, parents))
+	}
+
+	return FlowInfo{Name: name, DisplayName: f.Name, Steps: steps}
+}
+
+// describeStep builds the StepInfo for a step. The effective retry policy and
+// timeout are reported on executable steps only, since a group itself is never retried.
+func (c *CatalogService) describeStep(step flow.Step, parents map[string][]string) StepInfo {
+	info := StepInfo{
+		Name:        step.Name,
+		Description: step.Description,
+		Conditional: step.When != nil,
+		Branching:   step.Branch != nil,
+		Compensable: step.Compensate != nil,
+	}
+	if !step.IsGroup() {
+		info.DependsOn = parents[step.Name]
+		policy := step.Retry.Resolve(c.config.RetryPolicy())
+
+		info.Retry = &RetryInfo{
+			MaxAttempts:    policy.MaxAttempts,
+			InitialBackoff: policy.InitialBackoff.String(),
+			MaxBackoff:     policy.MaxBackoff.String(),
+			BackoffFactor:  policy.BackoffFactor,
+			JitterFraction: policy.Jitter(),
+		}
+		if policy.MaxElapsedTime > 0 {
+			info.Retry.MaxElapsedTime = policy.MaxElapsedTime.String()
+		}
+
+		timeout := step.Timeout
+		if timeout <= 0 {
+			timeout = helpers.Seconds(c.config.StepTimeout)
+		}
+		if timeout > 0 {
+			info.Timeout = timeout.String()
+		}
+		return info
+	}
+
+	for _, member := range step.Parallel {
+		info.Parallel = append(info.Parallel, c.describeStep(member, parents))
+	}
+	return info
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	// Go Internal Packages
+	"sync"
+
+	// Local Packages
+	models "flowx/models/event"
+	helpers "flowx/utils/helpers"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// bufferSize is how many events a subscriber can fall behind by.
+const bufferSize = 64
+
+// Bus carries the events of the runs executing on this instance to the
+// subscribers of each run. Publishing never blocks: a subscriber that falls
+// too far behind is dropped, which closes its channel.
+type Bus struct {
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	subs map[string]map[chan models.Event]struct{}
+}
+
+// NewBus creates an event bus with no subscribers.
+func NewBus(logger *zap.Logger) *Bus {
+	return &Bus{
+		logger: logger,
+		subs:   make(map[string]map[chan models.Event]struct{}),
+	}
+}
+
+// Publish stamps an event with the current time and hands it to every
+// subscriber of its run.
+func (b *Bus) Publish(event models.Event) {
+	event.Time = helpers.GetCurrentDateTime()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[event.RunID] {
+		select {
+		case ch <- event:
+		default:
+			b.logger.Warn("Dropping Slow Event Subscriber", zap.String("runId", event.RunID))
+			b.remove(event.RunID, ch)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the events of a run from now on,
+// and a func that unsubscribes. The channel is closed once unsubscribed.
+func (b *Bus) Subscribe(runID string) (<-chan models.Event, func()) {
+	ch := make(chan models.Event, bufferSize)
+
+	b.mu.Lock()
+	if b.subs[runID] == nil {
+		b.subs[runID] = make(map[chan models.Event]struct{})
+	}
+	b.subs[runID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(runID, ch)
+	}
+}
+
+// remove closes and unregisters a subscriber, unless it already was.
+// b.mu must be held.
+func (b *Bus) remove(runID string, ch chan models.Event) {
+	if _, ok := b.subs[runID][ch]; !ok {
+		return
+	}
+	delete(b.subs[runID], ch)
+	if len(b.subs[runID]) == 0 {
+		delete(b.subs, runID)
+	}
+	close(ch)
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"time"
+
+	// Local Packages
+	flow "flowx/flow"
+	runmodels "flowx/models/run"
+	srmodels "flowx/models/steprun"
+	helpers "flowx/utils/helpers"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// HasCompensation returns true if the run's flow defines any Compensate hook,
+// i.e. whether a failure of the run needs to be compensated.
+func (e *Executor) HasCompensation(run runmodels.Run) bool {
+	f, err := e.resolveFlow(run.Flow)
+	if err != nil {
+		return false
+	}
+	return f.HasCompensation()
+}
+
+// Compensate undoes the completed steps of a failed run by calling their
+// Compensate hooks in reverse flow order, so that every step is compensated
+// before the steps it depends on, each with the step's persisted output.
+// Every outcome is persisted on the step run, so a compensation interrupted
+// by shutdown resumes with the steps not yet compensated. It stops at the first step whose compensation fails.
+func (e *Executor) Compensate(ctx context.Context, workerID int, run runmodels.Run) error {
+	f, err := e.resolveFlow(run.Flow)
+	if err != nil {
+		return err
+	}
+
+	stepRuns, err := e.stepRunRepo.GetByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	recorded := make(map[string]srmodels.StepRun, len(stepRuns))
+	for _, sr := range stepRuns {
+		recorded[sr.ID.StepName] = sr
+	}
+
+	e.logger.Info("Compensating Run", zap.String("runId", run.ID), zap.String("flow", f.Name),
+		zap.Int("workerId", workerID))
+
+	nodes := f.SortedNodes()
+	for i := len(nodes) - 1; i >= 0; i-- {
+		step := nodes[i].Step
+		sr, ok := recorded[step.Name]
+		if !ok || step.Compensate == nil || !sr.IsEndedSuccessfully() || sr.IsCompensated() {
+			continue
+		}
+		if err = e.compensateStep(ctx, run.ID, workerID, step, sr.Ending.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compensateStep calls a step's Compensate hook, retrying it with the step's
+// retry policy. A hook interrupted by shutdown is not recorded, so that it is
+// called again when the compensation resumes.
+func (e *Executor) compensateStep(ctx context.Context, runID string, workerID int, step flow.Step, output map[string]any) error {
+	e.logger.Info(fmt.Sprintf("Compensating Step [%s]", step.Name),
+		zap.String("runId", runID), zap.Int("workerId", workerID))
+
+	var lastError error
+	startTime := time.Now()
+
+	policy := e.retryPolicy(step)
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		err := step.Compensate(ctx, output)
+		if err == nil {
+			if logErr := e.stepRunRepo.RecordCompensation(ctx, runID, step.Name, srmodels.CompensationCompleted, "", helpers.SecondsSince(startTime)); logErr != nil {
+				return fmt.Errorf("step logging failed (compensated): %w", logErr)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		lastError = err
+		if flow.IsPermanent(err) || attempt == policy.MaxAttempts {
+			break
+		}
+
+		backoff, requested := flow.RetryDelay(err)
+		if !requested {
+			backoff = e.calculateBackoff(policy, attempt)
+		}
+		e.logger.Warn(fmt.Sprintf("Compensation Of Step [%s] Failed, Retrying in %s", step.Name, backoff),
+			zap.String("runId", runID), zap.Int("workerId", workerID), zap.Int("attempt", attempt), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+
+	e.logger.Error(fmt.Sprintf("Compensation Of Step [%s] Failed", step.Name),
+		zap.String("runId", runID), zap.Int("workerId", workerID), zap.Error(lastError))
+
+	if logErr := e.stepRunRepo.RecordCompensation(ctx, runID, step.Name, srmodels.CompensationFailed, lastError.Error(), helpers.SecondsSince(startTime)); logErr != nil {
+		return fmt.Errorf("step logging failed (compensation): %w", logErr)
+	}
+	return fmt.Errorf("step %s: %w", step.Name, lastError)
+}
@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	// Local Packages
 	config "flowx/config"
+	errors "flowx/errors"
 	flow "flowx/flow"
+	evmodels "flowx/models/event"
+	runmodels "flowx/models/run"
 	srmodels "flowx/models/steprun"
 	helpers "flowx/utils/helpers"
 
@@ -20,86 +25,220 @@ import (
 
 // StepRunRepo defines the persistence operations needed by the executor.
 type StepRunRepo interface {
-	GetLastRecordedStep(ctx context.Context, runID string) (*srmodels.StepRun, error)
+	GetByRunID(ctx context.Context, runID string) ([]srmodels.StepRun, error)
 	RecordStepStart(ctx context.Context, runID, stepName string, input map[string]any) error
 	RecordStepEnd(ctx context.Context, runID, stepName, state, reason string, duration int, output map[string]any) error
+	RecordAttempt(ctx context.Context, runID, stepName string, attempt srmodels.Attempt) error
+	RecordCompensation(ctx context.Context, runID, stepName, state, reason string, duration int) error
+	Reset(ctx context.Context, runID, stepName string) error
 }
 
-// Executor is responsible for running the steps of a flow sequentially.
-// It handles step-level persistence, retries with exponential backoff + jitter,
-// and resume-from-failure logic.
+// Publisher publishes the progress of the runs being executed.
+type Publisher interface {
+	Publish(event evmodels.Event)
+}
+
+// errStepTimedOut is returned for an attempt that overran its step's timeout.
+var errStepTimedOut = errors.NewError("step timed out")
+
+// errBeforeAttempt wraps the error of a failed BeforeAttempt hook.
+var errBeforeAttempt = errors.NewError("before-attempt hook failed")
+
+// Executor is responsible for running the steps of a flow, each one as soon as
+// the steps it depends on have completed. It handles step-level persistence,
+// retries with exponential backoff + jitter, timeouts, and resume-from-failure
+// logic. The flow is resolved from the registry for every run, so one executor
+// serves all registered flows. Progress is logged and published as events.
 type Executor struct {
 	logger      *zap.Logger
 	stepRunRepo StepRunRepo
-	flow        flow.Flow
 	config      config.Executor
+	events      Publisher
+
+	// draining is set on shutdown; no new step is started once it is.
+	draining atomic.Bool
 }
 
-// NewService creates an Executor using the flow name from config to resolve
-// the flow definition from the registry.
-func NewService(logger *zap.Logger, config config.Executor, stepRunRepo StepRunRepo) *Executor {
+// NewService creates an Executor with the given retry configuration,
+// publishing the progress of runs to events.
+func NewService(logger *zap.Logger, config config.Executor, stepRunRepo StepRunRepo, events Publisher) *Executor {
 	return &Executor{
 		logger:      logger,
 		stepRunRepo: stepRunRepo,
-		flow:        flow.Get(config.Flow),
 		config:      config,
+		events:      events,
+	}
+}
+
+// Drain stops every run from starting new steps, while the steps already
+// executing carry on. A run left with steps to execute returns an Unavailable
+// error once its executing steps have finished, so that it can be resumed
+// later. Drain cannot be undone.
+func (e *Executor) Drain() {
+	e.draining.Store(true)
+}
+
+// DefaultFlow returns the registry name of the flow used for runs that were
+// created without naming one.
+func (e *Executor) DefaultFlow() string {
+	return e.config.Flow
+}
+
+// resolveFlow looks up the flow a run belongs to. Runs persisted before the
+// flow name was stored on them fall back to the default flow.
+func (e *Executor) resolveFlow(name string) (flow.Flow, error) {
+	if name == "" {
+		name = e.config.Flow
 	}
+	return flow.Get(name)
 }
 
-// StartRun determines where to begin execution for a run. If a previous step
-// was recorded (e.g. after a crash), it resumes from there; otherwise it
-// starts fresh with all steps.
-func (e *Executor) StartRun(ctx context.Context, workerID int, runID string, input map[string]any) error {
-	lastStep, err := e.stepRunRepo.GetLastRecordedStep(ctx, runID)
+// StartRun determines where to begin execution for a run from the step runs
+// persisted for it. Steps that already completed (e.g. before a crash) are not
+// executed again unless they were compensated; every other step runs once its
+// dependencies have completed. Once every step has finished, the run's output
+// is returned: the output of its last step.
+func (e *Executor) StartRun(ctx context.Context, workerID int, run runmodels.Run) (map[string]any, error) {
+	f, err := e.resolveFlow(run.Flow)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	stepRuns, err := e.stepRunRepo.GetByRunID(ctx, run.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	if lastStep == nil {
-		allSteps := e.flow.GetAllSteps()
-		e.logger.Info("Executing New Run", zap.String("runId", runID),
-			zap.Int("workerId", workerID), zap.Strings("steps", e.flow.StepNames()))
-		return e.executeSteps(ctx, runID, workerID, input, allSteps)
+	state := newRunState(f)
+	if len(stepRuns) == 0 {
+		e.logger.Info("Executing New Run", zap.String("runId", run.ID), zap.String("flow", f.Name),
+			zap.Int("workerId", workerID), zap.Strings("steps", f.StepNames()))
+		if err = e.executeSteps(ctx, run.ID, workerID, run.Input, f.GetPendingSteps(nil), state); err != nil {
+			return nil, err
+		}
+		return state.output(), nil
 	}
 
-	pendingSteps, resumeInput := e.findPendingSteps(lastStep)
+	// Skipped steps count as finished so that resuming never re-evaluates them.
+	completed := make(map[string]bool)
+	for _, sr := range stepRuns {
+		name := sr.ID.StepName
+		switch {
+		case sr.IsEndedSuccessfully() && !sr.IsCompensated():
+			state.outputs[name] = sr.Ending.Output
+		case sr.IsSkipped() && sr.Ending.Reason == srmodels.ReasonBranchNotTaken:
+			state.pruned[name] = true
+		case sr.IsSkipped():
+			state.outputs[name] = sr.Ending.Output
+			state.skipped[name] = true
+		default:
+			continue
+		}
+		completed[name] = true
+	}
+
+	// A step completed earlier is pending again if it depends on a pending
+	// step, e.g. one that was compensated; forget its earlier outcome so that
+	// the steps depending on it wait for it to execute again.
+	pendingSteps := f.GetPendingSteps(completed)
 	stepNames := make([]string, len(pendingSteps))
 	for i, s := range pendingSteps {
 		stepNames[i] = s.Name
+		delete(state.outputs, s.Name)
+		delete(state.skipped, s.Name)
+		delete(state.pruned, s.Name)
 	}
 
-	e.logger.Info("Resuming Run", zap.String("runId", runID),
+	e.logger.Info("Resuming Run", zap.String("runId", run.ID), zap.String("flow", f.Name),
 		zap.Int("workerId", workerID), zap.Strings("pendingSteps", stepNames))
-	return e.executeSteps(ctx, runID, workerID, resumeInput, pendingSteps)
+	if err = e.executeSteps(ctx, run.ID, workerID, run.Input, pendingSteps, state); err != nil {
+		return nil, err
+	}
+	return state.output(), nil
 }
 
-// executeSteps runs the given steps in order, chaining output → input between them.
-func (e *Executor) executeSteps(ctx context.Context, runID string, workerID int, initialInput map[string]any, steps []flow.Step) error {
-	input := initialInput
-	for _, step := range steps {
-		if err := e.stepRunRepo.RecordStepStart(ctx, runID, step.Name, input); err != nil {
-			return err
-		}
+// PrepareRerun makes the given step, and every step depending on it, execute
+// again on the next StartRun. The step reruns with the input it was originally
+// recorded with. It must be part of the run's flow and must have finished at
+// least once for the run.
+func (e *Executor) PrepareRerun(ctx context.Context, run runmodels.Run, stepName string) error {
+	f, err := e.resolveFlow(run.Flow)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(f.StepNames(), stepName) {
+		return errors.E(errors.Invalid, fmt.Sprintf("step %s not found in flow %s", stepName, f.Name))
+	}
 
-		e.logger.Info(fmt.Sprintf("Executing Step [%s]", step.Name),
-			zap.String("runId", runID), zap.Int("workerId", workerID))
+	stepRuns, err := e.stepRunRepo.GetByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
 
-		output, err := e.executeStepWithRetry(ctx, runID, workerID, input, step)
-		if err != nil {
+	finished := make(map[string]bool, len(stepRuns))
+	for _, sr := range stepRuns {
+		finished[sr.ID.StepName] = sr.Ending != nil
+	}
+	if !finished[stepName] {
+		return errors.E(errors.Invalid, fmt.Sprintf("step %s has not finished for this run", stepName))
+	}
+
+	for _, name := range append([]string{stepName}, f.Descendants(stepName)...) {
+		if !finished[name] {
+			continue
+		}
+		if err = e.stepRunRepo.Reset(ctx, run.ID, name); err != nil {
 			return err
 		}
-
-		input = output
 	}
 	return nil
 }
 
-// executeStepWithRetry attempts a step up to MaxRetries times with
-// exponential backoff and jitter between attempts.
+// executeSingle records the start of a step and executes it with retries.
+func (e *Executor) executeSingle(ctx context.Context, runID string, workerID int, input map[string]any, step flow.Step) (map[string]any, error) {
+	if err := e.stepRunRepo.RecordStepStart(ctx, runID, step.Name, input); err != nil {
+		return nil, err
+	}
+
+	e.logger.Info(fmt.Sprintf("Executing Step [%s]", step.Name),
+		zap.String("runId", runID), zap.Int("workerId", workerID))
+	e.events.Publish(evmodels.Event{Type: evmodels.StepStarted, RunID: runID, Step: step.Name})
+
+	return e.executeStepWithRetry(ctx, runID, workerID, input, step)
+}
+
+// retryPolicy returns the retry policy in effect for a step: its own policy
+// with any unset fields taken from the executor configuration.
+func (e *Executor) retryPolicy(step flow.Step) flow.RetryPolicy {
+	return step.Retry.Resolve(e.config.RetryPolicy())
+}
+
+// stepTimeout returns the time limit for a single attempt of a step: its own
+// Timeout if set, the configured default otherwise. Zero means no limit.
+func (e *Executor) stepTimeout(step flow.Step) time.Duration {
+	if step.Timeout > 0 {
+		return step.Timeout
+	}
+	return helpers.Seconds(e.config.StepTimeout)
+}
+
+// executeStepWithRetry attempts a step up to the policy's MaxAttempts with
+// exponential backoff and jitter between attempts, or the delay requested by
+// a flow.RetryAfter error. A flow.Permanent error fails the step at once, and
+// retrying also stops once the next attempt would start after MaxElapsedTime,
+// if the policy sets one. An attempt that times out is retried like any other
+// failure. Every attempt is recorded on the step run, and the reason recorded
+// on a FAILED step is prefixed with why it failed. The step's AfterSuccess or
+// OnFailure hook runs once the outcome is recorded. If the run's context is
+// cancelled mid-step, the step is recorded as CANCELLED and not retried.
 func (e *Executor) executeStepWithRetry(ctx context.Context, runID string, workerID int, input map[string]any, step flow.Step) (map[string]any, error) {
 	var lastError error
+	failure := srmodels.FailureRetriesExhausted
 
-	for attempt := 1; attempt <= e.config.MaxRetries; attempt++ {
+	policy := e.retryPolicy(step)
+	startTime := time.Now()
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		startedAt := helpers.GetCurrentDateTime()
 		output, sec, err := e.executeStep(ctx, step, input)
 		duration := time.Duration(sec) * time.Second
 
@@ -107,36 +246,147 @@ func (e *Executor) executeStepWithRetry(ctx context.Context, runID string, worke
 			e.logger.Info(fmt.Sprintf("Step [%s] Executed Successfully", step.Name), zap.Int("workerId", workerID),
 				zap.Duration("duration", duration), zap.Int("attempt", attempt))
 
-			if logErr := e.stepRunRepo.RecordStepEnd(ctx, runID, step.Name, "COMPLETED", "", sec, output); logErr != nil {
+			if logErr := e.recordAttempt(ctx, runID, step.Name, attempt, startedAt, sec, nil, 0); logErr != nil {
+				return nil, logErr
+			}
+			if logErr := e.stepRunRepo.RecordStepEnd(ctx, runID, step.Name, srmodels.StateCompleted, "", sec, output); logErr != nil {
 				return nil, fmt.Errorf("step logging failed (success): %w", logErr)
 			}
+			e.events.Publish(evmodels.Event{Type: evmodels.StepCompleted, RunID: runID, Step: step.Name,
+				Attempt: attempt, Output: output})
+			e.afterSuccess(ctx, runID, workerID, step, input, output)
 			return output, nil
 		}
 
-		lastError = err
+		if ctx.Err() != nil {
+			logCtx := context.WithoutCancel(ctx)
+			if logErr := e.recordAttempt(logCtx, runID, step.Name, attempt, startedAt, sec, context.Cause(ctx), 0); logErr != nil {
+				return nil, logErr
+			}
+			return nil, e.recordInterrupted(ctx, runID, workerID, step, sec)
+		}
 
-		if attempt < e.config.MaxRetries {
-			backoff := e.calculateBackoff(attempt)
-			e.logger.Warn(fmt.Sprintf("Step [%s] Failed, Retrying in %s", step.Name, backoff),
-				zap.Int("workerId", workerID), zap.Int("attempt", attempt), zap.Error(err))
+		lastError = err
 
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(backoff):
+		var backoff time.Duration
+		retry := false
+		switch {
+		case flow.IsPermanent(err):
+			failure = srmodels.FailurePermanent
+		case attempt == policy.MaxAttempts:
+			// Out of attempts, the failure stays RETRIES_EXHAUSTED.
+		default:
+			delay, requested := flow.RetryDelay(err)
+			if !requested {
+				delay = e.calculateBackoff(policy, attempt)
 			}
+			if policy.MaxElapsedTime > 0 && time.Since(startTime)+delay > policy.MaxElapsedTime {
+				failure = srmodels.FailureMaxElapsedTime
+			} else {
+				backoff, retry = delay, true
+			}
+		}
+
+		if logErr := e.recordAttempt(ctx, runID, step.Name, attempt, startedAt, sec, err, backoff); logErr != nil {
+			return nil, logErr
+		}
+		if !retry {
+			break
+		}
+
+		msg := fmt.Sprintf("Step [%s] Failed, Retrying in %s", step.Name, backoff)
+		if errors.Is(err, errBeforeAttempt) {
+			msg = fmt.Sprintf("Before-Attempt Hook Of Step [%s] Failed, Retrying in %s", step.Name, backoff)
+		}
+		e.logger.Warn(msg, zap.Int("workerId", workerID), zap.Int("attempt", attempt), zap.Error(err))
+		e.events.Publish(evmodels.Event{Type: evmodels.AttemptFailed, RunID: runID, Step: step.Name,
+			Attempt: attempt, Reason: err.Error(), Backoff: backoff.String()})
+
+		select {
+		case <-ctx.Done():
+			return nil, e.recordInterrupted(ctx, runID, workerID, step, sec)
+		case <-time.After(backoff):
 		}
 	}
 
-	e.logger.Error(fmt.Sprintf("Max Retries Reached, Step [%s] Failed", step.Name),
+	if errors.Is(lastError, errStepTimedOut) {
+		failure = srmodels.FailureTimedOut
+	}
+
+	e.logger.Error(fmt.Sprintf("Step [%s] Failed (%s)", step.Name, failure),
 		zap.Int("workerId", workerID), zap.Error(lastError))
 
-	if logErr := e.stepRunRepo.RecordStepEnd(ctx, runID, step.Name, "FAILED", lastError.Error(), -1, nil); logErr != nil {
+	reason := srmodels.FailureReason(failure, lastError)
+	if logErr := e.stepRunRepo.RecordStepEnd(ctx, runID, step.Name, srmodels.StateFailed, reason, -1, nil); logErr != nil {
 		return nil, fmt.Errorf("step logging failed (failure): %w", logErr)
 	}
+	e.events.Publish(evmodels.Event{Type: evmodels.StepFailed, RunID: runID, Step: step.Name, Reason: reason})
+	e.onFailure(ctx, runID, workerID, step, input, lastError)
 	return nil, lastError
 }
 
+// afterSuccess runs the AfterSuccess hook of a completed step, if it has one.
+// The step has already been recorded as COMPLETED, so an error is only logged.
+func (e *Executor) afterSuccess(ctx context.Context, runID string, workerID int, step flow.Step, input, output map[string]any) {
+	if step.AfterSuccess == nil {
+		return
+	}
+	if err := step.AfterSuccess(ctx, input, output); err != nil {
+		e.logger.Warn(fmt.Sprintf("After-Success Hook Of Step [%s] Failed", step.Name),
+			zap.String("runId", runID), zap.Int("workerId", workerID), zap.Error(err))
+	}
+}
+
+// onFailure runs the OnFailure hook of a step that failed for good, if it
+// has one. The step has already been recorded as FAILED, so an error is only
+// logged.
+func (e *Executor) onFailure(ctx context.Context, runID string, workerID int, step flow.Step, input map[string]any, stepErr error) {
+	if step.OnFailure == nil {
+		return
+	}
+	if err := step.OnFailure(ctx, input, stepErr); err != nil {
+		e.logger.Warn(fmt.Sprintf("On-Failure Hook Of Step [%s] Failed", step.Name),
+			zap.String("runId", runID), zap.Int("workerId", workerID), zap.Error(err))
+	}
+}
+
+// recordAttempt persists one attempt of a step. err is the error the attempt
+// failed with, nil if it succeeded, and backoff the wait before the next one.
+func (e *Executor) recordAttempt(ctx context.Context, runID, stepName string, number int, startedAt string, sec int, err error, backoff time.Duration) error {
+	attempt := srmodels.Attempt{
+		Number:    number,
+		StartedAt: startedAt,
+		EndedAt:   helpers.GetCurrentDateTime(),
+		Duration:  sec,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if backoff > 0 {
+		attempt.Backoff = backoff.String()
+	}
+
+	if logErr := e.stepRunRepo.RecordAttempt(ctx, runID, stepName, attempt); logErr != nil {
+		return fmt.Errorf("step logging failed (attempt): %w", logErr)
+	}
+	return nil
+}
+
+// recordInterrupted marks a step as CANCELLED after the run's context was
+// cancelled, using the cancellation cause as the reason. The write uses a
+// context detached from cancellation so that it still reaches the database.
+func (e *Executor) recordInterrupted(ctx context.Context, runID string, workerID int, step flow.Step, sec int) error {
+	cause := context.Cause(ctx)
+	e.logger.Warn(fmt.Sprintf("Step [%s] Interrupted", step.Name), zap.String("runId", runID),
+		zap.Int("workerId", workerID), zap.Error(cause))
+
+	logCtx := context.WithoutCancel(ctx)
+	if logErr := e.stepRunRepo.RecordStepEnd(logCtx, runID, step.Name, srmodels.StateCancelled, cause.Error(), sec, nil); logErr != nil {
+		return fmt.Errorf("step logging failed (cancelled): %w", logErr)
+	}
+	return cause
+}
+
 // calculateBackoff computes the wait duration for a given retry attempt using
 // exponential backoff capped at MaxBackoff, with random jitter applied.
 //
@@ -145,17 +395,16 @@ func (e *Executor) executeStepWithRetry(ctx context.Context, runID string, worke
 //	attempt 1 → 30s  ± 20%
 //	attempt 2 → 60s  ± 20%
 //	attempt 3 → 120s ± 20%
-func (e *Executor) calculateBackoff(attempt int) time.Duration {
-	initialBackoff := float64(e.config.InitialBackoff) * float64(time.Second)
-	base := initialBackoff * math.Pow(e.config.BackoffFactor, float64(attempt-1))
+func (e *Executor) calculateBackoff(policy flow.RetryPolicy, attempt int) time.Duration {
+	base := float64(policy.InitialBackoff) * math.Pow(policy.BackoffFactor, float64(attempt-1))
 
-	maxBackoff := float64(e.config.MaxBackoff) * float64(time.Second)
+	maxBackoff := float64(policy.MaxBackoff)
 	if base > maxBackoff {
 		base = maxBackoff
 	}
 
 	// Apply jitter: shift by a random amount within ±(jitterFraction * base)
-	jitterRange := base * e.config.JitterFraction
+	jitterRange := base * policy.Jitter()
 	jitter := (rand.Float64()*2 - 1) * jitterRange
 	base += jitter
 
@@ -166,38 +415,58 @@ func (e *Executor) calculateBackoff(attempt int) time.Duration {
 	return time.Duration(base)
 }
 
-// executeStep runs cleanup (if defined) followed by execution, tracking elapsed time.
+// executeStep runs one attempt of a step, its BeforeAttempt hook (if defined)
+// followed by Execute, tracking elapsed time. Both share the step's timeout,
+// if it has one: they get a context that expires with it, and a step still
+// running when it expires is abandoned and errStepTimedOut returned, so that
+// a step ignoring its context cannot hold on to the worker.
 func (e *Executor) executeStep(ctx context.Context, step flow.Step, input map[string]any) (map[string]any, int, error) {
 	startTime := time.Now()
 
-	if step.Cleanup != nil {
-		if err := step.Cleanup(ctx, input); err != nil {
-			return nil, helpers.SecondsSince(startTime), err
-		}
+	timeout := e.stepTimeout(step)
+	if timeout <= 0 {
+		output, err := runPhases(ctx, step, input)
+		return output, helpers.SecondsSince(startTime), err
 	}
 
-	if step.Execute != nil {
-		output, err := step.Execute(ctx, input)
-		if err != nil {
-			return nil, helpers.SecondsSince(startTime), err
+	stepCtx, cancel := context.WithTimeoutCause(ctx, timeout, errStepTimedOut)
+	defer cancel()
+
+	type result struct {
+		output map[string]any
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := runPhases(stepCtx, step, input)
+		done <- result{output: output, err: err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		if res.err != nil && ctx.Err() == nil && errors.Is(context.Cause(stepCtx), errStepTimedOut) {
+			res.err = fmt.Errorf("%w after %s: %w", errStepTimedOut, timeout, res.err)
 		}
-		return output, helpers.SecondsSince(startTime), nil
+		return res.output, helpers.SecondsSince(startTime), res.err
+	case <-timer.C:
+		return nil, helpers.SecondsSince(startTime), fmt.Errorf("%w after %s", errStepTimedOut, timeout)
 	}
-
-	return input, helpers.SecondsSince(startTime), nil
 }
 
-// findPendingSteps determines which steps remain based on the last recorded step.
-// If the last step succeeded, resume from the next one using its output.
-// If it failed, re-run it using its original input.
-func (e *Executor) findPendingSteps(lastStep *srmodels.StepRun) ([]flow.Step, map[string]any) {
-	succeeded := lastStep.IsEndedSuccessfully()
-	lastStepName := lastStep.ID.StepName
-
-	pendingSteps := e.flow.GetPendingSteps(lastStepName, succeeded)
-	if succeeded {
-		return pendingSteps, lastStep.Ending.Output
+// runPhases runs the step's BeforeAttempt hook (if defined) followed by its
+// execution. A step without Execute passes its input through.
+func runPhases(ctx context.Context, step flow.Step, input map[string]any) (map[string]any, error) {
+	if step.BeforeAttempt != nil {
+		if err := step.BeforeAttempt(ctx, input); err != nil {
+			return nil, fmt.Errorf("%w: %w", errBeforeAttempt, err)
+		}
 	}
 
-	return pendingSteps, lastStep.Input
+	if step.Execute != nil {
+		return step.Execute(ctx, input)
+	}
+	return input, nil
 }
@@ -0,0 +1,322 @@
+package executor
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	// Local Packages
+	config "flowx/config"
+	errors "flowx/errors"
+	flow "flowx/flow"
+	runmodels "flowx/models/run"
+	srmodels "flowx/models/steprun"
+	events "flowx/services/events"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// fakeStepRunRepo keeps step runs in memory, recording what was written.
+// GetByRunID returns stepRuns, the step runs of an earlier execution.
+type fakeStepRunRepo struct {
+	mu       sync.Mutex
+	stepRuns []srmodels.StepRun
+	ends     []srmodels.StepEndState
+	attempts []srmodels.Attempt
+}
+
+func (f *fakeStepRunRepo) GetByRunID(ctx context.Context, runID string) ([]srmodels.StepRun, error) {
+	return f.stepRuns, nil
+}
+
+func (f *fakeStepRunRepo) RecordStepStart(ctx context.Context, runID, stepName string, input map[string]any) error {
+	return nil
+}
+
+func (f *fakeStepRunRepo) RecordStepEnd(ctx context.Context, runID, stepName, state, reason string, duration int, output map[string]any) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ends = append(f.ends, srmodels.StepEndState{EndState: state, Reason: reason, Output: output, Duration: duration})
+	return nil
+}
+
+func (f *fakeStepRunRepo) RecordAttempt(ctx context.Context, runID, stepName string, attempt srmodels.Attempt) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts = append(f.attempts, attempt)
+	return nil
+}
+
+func (f *fakeStepRunRepo) RecordCompensation(ctx context.Context, runID, stepName, state, reason string, duration int) error {
+	return nil
+}
+
+func (f *fakeStepRunRepo) Reset(ctx context.Context, runID, stepName string) error {
+	return nil
+}
+
+// newTestExecutor returns an executor allowing three attempts per step with
+// a negligible backoff between them.
+func newTestExecutor(repo StepRunRepo) *Executor {
+	conf := config.Executor{
+		MaxRetries:     3,
+		InitialBackoff: 0.001,
+		MaxBackoff:     0.001,
+		BackoffFactor:  1,
+	}
+	return NewService(zap.NewNop(), conf, repo, events.NewBus(zap.NewNop()))
+}
+
+// hookedStep returns a step whose hooks and Execute append to calls. Execute
+// fails with executeErr for its first failures attempts, and BeforeAttempt
+// fails with beforeErr on the attempts listed in beforeFails.
+func hookedStep(calls *[]string, failures int, executeErr error, beforeFails []int, beforeErr error) flow.Step {
+	attempt := 0
+	return flow.Step{
+		Name: "step",
+		BeforeAttempt: func(ctx context.Context, input map[string]any) error {
+			attempt++
+			*calls = append(*calls, fmt.Sprintf("before:%d", attempt))
+			if slices.Contains(beforeFails, attempt) {
+				return beforeErr
+			}
+			return nil
+		},
+		Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			*calls = append(*calls, fmt.Sprintf("execute:%d", attempt))
+			if attempt <= failures {
+				return nil, executeErr
+			}
+			return map[string]any{"done": true}, nil
+		},
+		AfterSuccess: func(ctx context.Context, input, output map[string]any) error {
+			*calls = append(*calls, "after_success")
+			return nil
+		},
+		OnFailure: func(ctx context.Context, input map[string]any, err error) error {
+			*calls = append(*calls, "on_failure:"+err.Error())
+			return nil
+		},
+	}
+}
+
+func TestHookOrderingAcrossRetries(t *testing.T) {
+	boom := errors.NewError("boom")
+	reset := errors.NewError("reset failed")
+
+	tests := []struct {
+		name        string
+		failures    int
+		executeErr  error
+		beforeFails []int
+		wantCalls   []string
+		wantState   string
+		wantReason  string
+	}{
+		{
+			name:       "succeeds first time",
+			wantCalls:  []string{"before:1", "execute:1", "after_success"},
+			wantState:  srmodels.StateCompleted,
+			wantReason: "",
+		},
+		{
+			name:       "succeeds after retries",
+			failures:   2,
+			executeErr: boom,
+			wantCalls: []string{
+				"before:1", "execute:1",
+				"before:2", "execute:2",
+				"before:3", "execute:3",
+				"after_success",
+			},
+			wantState:  srmodels.StateCompleted,
+			wantReason: "",
+		},
+		{
+			name:       "exhausts retries",
+			failures:   3,
+			executeErr: boom,
+			wantCalls: []string{
+				"before:1", "execute:1",
+				"before:2", "execute:2",
+				"before:3", "execute:3",
+				"on_failure:boom",
+			},
+			wantState:  srmodels.StateFailed,
+			wantReason: "RETRIES_EXHAUSTED: boom",
+		},
+		{
+			name:        "before-attempt failure skips execute and is retried",
+			beforeFails: []int{1},
+			wantCalls: []string{
+				"before:1",
+				"before:2", "execute:2",
+				"after_success",
+			},
+			wantState:  srmodels.StateCompleted,
+			wantReason: "",
+		},
+		{
+			name:       "permanent failure is not retried",
+			failures:   3,
+			executeErr: flow.Permanent(boom),
+			wantCalls:  []string{"before:1", "execute:1", "on_failure:boom"},
+			wantState:  srmodels.StateFailed,
+			wantReason: "PERMANENT: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			repo := &fakeStepRunRepo{}
+			e := newTestExecutor(repo)
+			step := hookedStep(&calls, tt.failures, tt.executeErr, tt.beforeFails, reset)
+
+			_, err := e.executeStepWithRetry(context.Background(), "run", 0, map[string]any{}, step)
+			if (err != nil) != (tt.wantState == srmodels.StateFailed) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if len(repo.ends) != 1 {
+				t.Fatalf("recorded %d endings, want 1", len(repo.ends))
+			}
+			if got := repo.ends[0]; got.EndState != tt.wantState || got.Reason != tt.wantReason {
+				t.Errorf("ending = %s %q, want %s %q", got.EndState, got.Reason, tt.wantState, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestBeforeAttemptErrorIsRecordedOnAttempt(t *testing.T) {
+	var calls []string
+	repo := &fakeStepRunRepo{}
+	e := newTestExecutor(repo)
+	step := hookedStep(&calls, 0, nil, []int{1, 2, 3}, errors.NewError("reset failed"))
+
+	_, err := e.executeStepWithRetry(context.Background(), "run", 0, map[string]any{}, step)
+	if !errors.Is(err, errBeforeAttempt) {
+		t.Fatalf("err = %v, want a before-attempt error", err)
+	}
+	if len(repo.attempts) != 3 {
+		t.Fatalf("recorded %d attempts, want 3", len(repo.attempts))
+	}
+	for i, a := range repo.attempts {
+		if a.Number != i+1 || !strings.Contains(a.Error, "reset failed") {
+			t.Errorf("attempt %d = %+v", i+1, a)
+		}
+	}
+	if slices.Contains(calls, "execute:1") {
+		t.Errorf("execute ran despite failing before-attempt hook: %v", calls)
+	}
+}
+
+func TestHookErrorsDoNotChangeOutcome(t *testing.T) {
+	repo := &fakeStepRunRepo{}
+	e := newTestExecutor(repo)
+	step := flow.Step{
+		Name: "step",
+		Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+			return map[string]any{"done": true}, nil
+		},
+		AfterSuccess: func(ctx context.Context, input, output map[string]any) error {
+			return errors.NewError("notify failed")
+		},
+	}
+
+	output, err := e.executeStepWithRetry(context.Background(), "run", 0, map[string]any{}, step)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output["done"] != true {
+		t.Errorf("output = %v", output)
+	}
+	if repo.ends[0].EndState != srmodels.StateCompleted {
+		t.Errorf("end state = %s, want %s", repo.ends[0].EndState, srmodels.StateCompleted)
+	}
+}
+
+func TestOnFailureNotCalledWhenCancelled(t *testing.T) {
+	var calls []string
+	repo := &fakeStepRunRepo{}
+	e := newTestExecutor(repo)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	step := hookedStep(&calls, 3, errors.NewError("boom"), nil, nil)
+	step.Execute = func(ctx context.Context, input map[string]any) (map[string]any, error) {
+		cancel(errors.NewError("run cancelled by operator"))
+		return nil, ctx.Err()
+	}
+
+	if _, err := e.executeStepWithRetry(ctx, "run", 0, map[string]any{}, step); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !slices.Equal(calls, []string{"before:1"}) {
+		t.Errorf("calls = %v, want only the first before-attempt hook", calls)
+	}
+	if repo.ends[0].EndState != srmodels.StateCancelled {
+		t.Errorf("end state = %s, want %s", repo.ends[0].EndState, srmodels.StateCancelled)
+	}
+}
+
+func TestRetryAfterCompensationReexecutesDownstreamStepsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var cInput map[string]any
+	step := func(name string) flow.Step {
+		return flow.Step{
+			Name: name,
+			Execute: func(ctx context.Context, input map[string]any) (map[string]any, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				if name == "c" {
+					cInput = input
+				}
+				return map[string]any{"from": name, "fresh": true}, nil
+			},
+		}
+	}
+	a := step("a")
+	a.Compensate = func(ctx context.Context, output map[string]any) error { return nil }
+	if err := flow.Register("test_retry_after_compensation", flow.Flow{
+		Name:  "test_retry_after_compensation",
+		Steps: []flow.Step{a, step("b"), step("c")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a completed and was compensated after c failed; b completed.
+	completed := func(name string) srmodels.StepRun {
+		return srmodels.StepRun{
+			ID:     srmodels.StepRunID{RunID: "run", StepName: name},
+			Ending: &srmodels.StepEndState{EndState: srmodels.StateCompleted, Output: map[string]any{"from": name}},
+		}
+	}
+	compensated := completed("a")
+	compensated.Compensation = &srmodels.CompensationState{State: srmodels.CompensationCompleted}
+	failed := srmodels.StepRun{
+		ID:     srmodels.StepRunID{RunID: "run", StepName: "c"},
+		Ending: &srmodels.StepEndState{EndState: srmodels.StateFailed},
+	}
+	repo := &fakeStepRunRepo{stepRuns: []srmodels.StepRun{compensated, completed("b"), failed}}
+	e := newTestExecutor(repo)
+
+	run := runmodels.Run{ID: "run", Flow: "test_retry_after_compensation", Input: map[string]any{}}
+	if _, err := e.StartRun(context.Background(), 0, run); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(order, want) {
+		t.Errorf("executed %v, want %v", order, want)
+	}
+	if cInput["fresh"] != true {
+		t.Errorf("c received %v, want the output of b's new execution", cInput)
+	}
+}
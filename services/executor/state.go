@@ -0,0 +1,239 @@
+package executor
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	// Local Packages
+	errors "flowx/errors"
+	flow "flowx/flow"
+	evmodels "flowx/models/event"
+	srmodels "flowx/models/steprun"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// runState tracks what is known about the steps of a run while it executes.
+// It is only ever touched by the scheduling loop in executeSteps.
+type runState struct {
+	order   []string // step names in flow order
+	steps   map[string]flow.Step
+	outputs map[string]map[string]any // completed steps, and steps skipped by their When
+	skipped map[string]bool           // steps skipped by their When
+	pruned  map[string]bool           // steps skipped because their branch was not taken
+	choices map[string]string         // branch decisions, by branch step name
+}
+
+func newRunState(f flow.Flow) *runState {
+	var order []string
+	steps := make(map[string]flow.Step)
+	for _, node := range f.Nodes() {
+		order = append(order, node.Name)
+		steps[node.Name] = node.Step
+	}
+	return &runState{
+		order:   order,
+		steps:   steps,
+		outputs: make(map[string]map[string]any),
+		skipped: make(map[string]bool),
+		pruned:  make(map[string]bool),
+		choices: make(map[string]string),
+	}
+}
+
+// isReady returns true once every dependency of the step has finished,
+// whether it completed or was skipped.
+func (s *runState) isReady(node flow.Node) bool {
+	for _, parent := range node.Parents {
+		if _, ok := s.outputs[parent]; !ok && !s.pruned[parent] {
+			return false
+		}
+	}
+	return true
+}
+
+// output returns the output of a finished run: that of its last step, in
+// flow order, that completed or was skipped by its When. Steps whose branch
+// was not taken are passed over.
+func (s *runState) output() map[string]any {
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if output, ok := s.outputs[s.order[i]]; ok {
+			return output
+		}
+	}
+	return nil
+}
+
+// inputFor builds a step's input. A root step receives the run input; any
+// other step receives the outputs of its dependencies merged in the order
+// they are declared, ignoring dependencies whose branch was not taken. Since
+// those outputs are persisted, a step resumed or rerun after its dependencies
+// finished gets the same input it was first recorded with.
+func (s *runState) inputFor(node flow.Node, runInput map[string]any) map[string]any {
+	if len(node.Parents) == 0 {
+		return runInput
+	}
+
+	merged := make(map[string]any)
+	for _, parent := range node.Parents {
+		maps.Copy(merged, s.outputs[parent])
+	}
+	return merged
+}
+
+// isBranchNotTaken returns true if none of the step's dependencies lead to it:
+// each one was itself pruned, or is a branch step that chose another path.
+// A branch step skipped by its When makes no choice and leads to every
+// dependent. Branch decisions are derived from persisted outputs, so they
+// come out the same when a run is resumed.
+func (s *runState) isBranchNotTaken(ctx context.Context, node flow.Node) (bool, error) {
+	if len(node.Parents) == 0 {
+		return false, nil
+	}
+
+	for _, parent := range node.Parents {
+		if s.pruned[parent] {
+			continue
+		}
+
+		step := s.steps[parent]
+		if step.Branch == nil || s.skipped[parent] {
+			return false, nil
+		}
+
+		chosen, ok := s.choices[parent]
+		if !ok {
+			var err error
+			chosen, err = step.Branch(ctx, s.outputs[parent])
+			if err != nil {
+				return false, fmt.Errorf("branch of step %s failed: %w", parent, err)
+			}
+			s.choices[parent] = chosen
+		}
+		if chosen == node.Name {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// stepResult is the outcome of one step executed by executeSteps.
+type stepResult struct {
+	name   string
+	output map[string]any
+	err    error
+}
+
+// executeSteps runs the given steps, starting each one as soon as all of its
+// dependencies have finished, so independent steps execute concurrently.
+// Steps whose branch was not taken or whose condition does not hold are
+// recorded as SKIPPED instead of being executed. Once a step fails, or the
+// executor is drained, no new steps are started; the steps already running
+// are allowed to finish, after which the errors of every failed step are
+// returned. A drained run without failures returns an Unavailable error.
+func (e *Executor) executeSteps(ctx context.Context, runID string, workerID int, runInput map[string]any, steps []flow.Node, state *runState) error {
+	results := make(chan stepResult)
+	waiting := slices.Clone(steps)
+	running := 0
+	var errs []error
+
+	for {
+		// Skipping a step finishes it immediately, which may make more steps
+		// ready, so keep scheduling until nothing changes.
+		for progressed := true; progressed && len(errs) == 0 && !e.draining.Load(); {
+			progressed = false
+
+			var blocked []flow.Node
+			for _, node := range waiting {
+				if len(errs) > 0 || !state.isReady(node) {
+					blocked = append(blocked, node)
+					continue
+				}
+
+				input := state.inputFor(node, runInput)
+				skipped, err := e.skipIfNeeded(ctx, runID, workerID, node, input, state)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if skipped {
+					progressed = true
+					continue
+				}
+
+				running++
+				go func() {
+					output, err := e.executeSingle(ctx, runID, workerID, input, node.Step)
+					results <- stepResult{name: node.Name, output: output, err: err}
+				}()
+			}
+			waiting = blocked
+		}
+
+		if running == 0 {
+			break
+		}
+
+		res := <-results
+		running--
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		state.outputs[res.name] = res.output
+	}
+
+	if len(errs) == 0 && len(waiting) > 0 && e.draining.Load() {
+		return errors.E(errors.Unavailable, "run paused for shutdown")
+	}
+	return errors.Join(errs...)
+}
+
+// skipIfNeeded records the step as SKIPPED if its branch was not taken or its
+// When condition does not hold, and updates the run state accordingly.
+func (e *Executor) skipIfNeeded(ctx context.Context, runID string, workerID int, node flow.Node, input map[string]any, state *runState) (bool, error) {
+	notTaken, err := state.isBranchNotTaken(ctx, node)
+	if err != nil {
+		return false, err
+	}
+	if notTaken {
+		state.pruned[node.Name] = true
+		return true, e.recordSkipped(ctx, runID, workerID, node.Name, srmodels.ReasonBranchNotTaken, input, nil)
+	}
+
+	if node.When == nil {
+		return false, nil
+	}
+
+	ok, err := node.When(ctx, input)
+	if err != nil {
+		return false, fmt.Errorf("condition of step %s failed: %w", node.Name, err)
+	}
+	if ok {
+		return false, nil
+	}
+
+	state.outputs[node.Name] = input
+	state.skipped[node.Name] = true
+	return true, e.recordSkipped(ctx, runID, workerID, node.Name, srmodels.ReasonConditionNotMet, input, input)
+}
+
+// recordSkipped persists a step run for a step that was not executed, so that
+// the run timeline shows it and resuming does not evaluate it again.
+func (e *Executor) recordSkipped(ctx context.Context, runID string, workerID int, stepName, reason string, input, output map[string]any) error {
+	e.logger.Info(fmt.Sprintf("Skipping Step [%s]", stepName), zap.String("runId", runID),
+		zap.Int("workerId", workerID), zap.String("reason", reason))
+
+	if err := e.stepRunRepo.RecordStepStart(ctx, runID, stepName, input); err != nil {
+		return err
+	}
+	if err := e.stepRunRepo.RecordStepEnd(ctx, runID, stepName, srmodels.StateSkipped, reason, 0, output); err != nil {
+		return fmt.Errorf("step logging failed (skipped): %w", err)
+	}
+	e.events.Publish(evmodels.Event{Type: evmodels.StepSkipped, RunID: runID, Step: stepName, Reason: reason})
+	return nil
+}
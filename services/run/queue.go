@@ -0,0 +1,72 @@
+package run
+
+import (
+	// Go Internal Packages
+	"context"
+	"time"
+
+	// Local Packages
+	qmodels "flowx/models/queue"
+)
+
+// Queue holds the runs waiting for a worker. A run popped from the queue is
+// claimed by the worker until it is acked or released; claims that are
+// neither extended nor acked in time may be delivered again.
+type Queue interface {
+	Push(ctx context.Context, runID string) error
+	Pop(ctx context.Context) (*qmodels.Item, error)
+	Extend(ctx context.Context, item *qmodels.Item) error
+	Ack(ctx context.Context, item *qmodels.Item) error
+	Release(ctx context.Context, item *qmodels.Item) error
+}
+
+// ChannelQueue is an in-memory Queue backed by a buffered channel. Runs are
+// lost on restart (recovery re-enqueues them) and Push blocks while the
+// channel is full, so it is meant for tests and single-instance setups.
+type ChannelQueue struct {
+	items chan *qmodels.Item
+}
+
+// NewChannelQueue creates a ChannelQueue holding up to size runs.
+func NewChannelQueue(size int) *ChannelQueue {
+	return &ChannelQueue{items: make(chan *qmodels.Item, size)}
+}
+
+// Push adds a run to the queue, blocking while it is full.
+func (q *ChannelQueue) Push(ctx context.Context, runID string) error {
+	now := time.Now().UTC()
+	item := &qmodels.Item{RunID: runID, EnqueuedAt: now, VisibleAt: now}
+	select {
+	case q.items <- item:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pop takes the next run, blocking until one is available or ctx is done.
+func (q *ChannelQueue) Pop(ctx context.Context) (*qmodels.Item, error) {
+	select {
+	case item := <-q.items:
+		item.Deliveries++
+		return item, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Extend is a no-op, a popped run is never delivered again.
+func (q *ChannelQueue) Extend(ctx context.Context, item *qmodels.Item) error {
+	return nil
+}
+
+// Ack is a no-op, a popped run has already left the queue.
+func (q *ChannelQueue) Ack(ctx context.Context, item *qmodels.Item) error {
+	return nil
+}
+
+// Release is a no-op, a popped run is not delivered again; recovery
+// re-enqueues it on the next startup.
+func (q *ChannelQueue) Release(ctx context.Context, item *qmodels.Item) error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package run
+
+import (
+	// Go Internal Packages
+	"context"
+	"time"
+
+	// External Packages
+	"go.uber.org/zap"
+)
+
+// dueBatchSize caps how many due runs are queued per scheduler tick.
+const dueBatchSize = 100
+
+// schedule periodically queues the scheduled runs that are due. Since the
+// schedule lives in the repository, runs scheduled before a restart are
+// queued by whichever instance finds them due first.
+func (s *RunService) schedule(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.schedulePoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Keep going while full batches are due, e.g. after downtime.
+		for {
+			if n := s.promoteDue(ctx); n < dueBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// promoteDue queues a batch of due runs and moves them into PENDING, and
+// returns how many runs were due. A run is queued before it is promoted so
+// that a crash in between leaves it SCHEDULED, and queued again on the next
+// tick; queueing it twice is harmless, as its lease lets only one worker
+// execute it.
+func (s *RunService) promoteDue(ctx context.Context) int {
+	due, err := s.runRepo.GetDue(ctx, time.Now().UTC(), dueBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("Failed To Load Due Runs", zap.Error(err))
+		}
+		return 0
+	}
+
+	for _, run := range due {
+		if err := s.enqueue(ctx, run.ID); err != nil {
+			return 0
+		}
+		if _, err := s.runRepo.Promote(ctx, run.ID); err != nil {
+			s.logger.Error("Failed To Promote Scheduled Run", zap.String("runId", run.ID), zap.Error(err))
+			return 0
+		}
+	}
+	return len(due)
+}
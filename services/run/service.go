@@ -4,11 +4,19 @@ import (
 	// Go Internal Packages
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	// Local Packages
 	config "flowx/config"
+	errors "flowx/errors"
+	flow "flowx/flow"
+	evmodels "flowx/models/event"
+	qmodels "flowx/models/queue"
 	models "flowx/models/run"
+	srmodels "flowx/models/steprun"
 	helpers "flowx/utils/helpers"
 	slack "flowx/utils/slack"
 
@@ -20,125 +28,919 @@ import (
 // RunRepository defines the persistence operations the service needs for runs.
 type RunRepository interface {
 	Create(ctx context.Context, run models.Run) error
+	Get(ctx context.Context, runID string) (*models.Run, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Run, error)
 	GetIncomplete(ctx context.Context) ([]models.Run, error)
-	MarkComplete(ctx context.Context, runID string) error
+	GetOrphaned(ctx context.Context) ([]models.Run, error)
+	GetStalePending(ctx context.Context, before time.Time) ([]models.Run, error)
+	GetDue(ctx context.Context, now time.Time, limit int) ([]models.Run, error)
+	Promote(ctx context.Context, runID string) (bool, error)
+	AcquireLease(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error)
+	RenewLease(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, runID, owner string) error
+	List(ctx context.Context, query models.ListQuery) ([]models.Run, string, error)
+	MarkRunning(ctx context.Context, runID string) (bool, error)
+	MarkCompensating(ctx context.Context, runID, reason string) (bool, error)
+	MarkComplete(ctx context.Context, runID string, output map[string]any) (bool, error)
+	MarkFailed(ctx context.Context, runID, reason string) (bool, error)
+	MarkCancelled(ctx context.Context, runID, reason string) (bool, error)
+	Reopen(ctx context.Context, runID string, from []models.Status) (bool, error)
+}
+
+// StepRunRepository defines the read operations the service needs for step runs.
+type StepRunRepository interface {
+	GetByRunID(ctx context.Context, runID string) ([]srmodels.StepRun, error)
 }
 
 // Executor defines the step-execution contract used by the run service.
 type Executor interface {
-	StartRun(ctx context.Context, workerID int, runID string, input map[string]any) error
+	DefaultFlow() string
+	StartRun(ctx context.Context, workerID int, run models.Run) (map[string]any, error)
+	PrepareRerun(ctx context.Context, run models.Run, stepName string) error
+	HasCompensation(run models.Run) bool
+	Compensate(ctx context.Context, workerID int, run models.Run) error
+	Drain()
+}
+
+// EventBus carries the events of runs to the subscribers of each run.
+type EventBus interface {
+	Publish(event evmodels.Event)
+	Subscribe(runID string) (<-chan evmodels.Event, func())
 }
 
-// RunService is the core orchestrator. It creates runs, manages the
-// buffered queue, spawns workers, and handles recovery on startup.
+// errCancelled is the cancellation cause attached to a run's context when an
+// operator cancels it. It ends up as the reason on the interrupted step.
+var errCancelled = errors.NewError("run cancelled by operator")
+
+// errShutdown is the cancellation cause attached to the context of the runs
+// still executing when the drain timeout passes on shutdown.
+var errShutdown = errors.NewError("interrupted by shutdown")
+
+// errLeaseLost is the cancellation cause attached to a run's context when
+// this instance fails to renew its lease, as another instance may take over.
+var errLeaseLost = errors.NewError("run lease lost")
+
+// errDeadlineExceeded is the cancellation cause attached to a run's context
+// once its deadline passes.
+var errDeadlineExceeded = errors.NewError("run deadline exceeded")
+
+// RunService is the core orchestrator. It creates runs, pushes them onto the
+// queue, spawns the workers consuming it, and handles recovery on startup.
 type RunService struct {
-	logger   *zap.Logger
-	runRepo  RunRepository
-	executor Executor
-	queue    chan models.Run
-	workers  int
-	wg       sync.WaitGroup
-	slack    slack.Sender
-}
-
-// NewService creates a RunService with the given queue configuration.
-func NewService(logger *zap.Logger, conf config.Queue, runRepo RunRepository, executor Executor, slack slack.Sender) *RunService {
-	queue := make(chan models.Run, conf.Size)
+	logger      *zap.Logger
+	runRepo     RunRepository
+	stepRunRepo StepRunRepository
+	executor    Executor
+	queue       Queue
+	workers     int
+	extendEvery time.Duration
+	wg          sync.WaitGroup
+	slack       slack.Sender
+
+	// instanceID identifies this instance as the owner of run leases.
+	instanceID    string
+	leaseDuration time.Duration
+	sweepInterval time.Duration
+
+	// schedulePoll is how often scheduled runs are checked for being due.
+	schedulePoll time.Duration
+
+	// draining is set by Shutdown, after which new runs are refused.
+	// stopIntake stops workers from claiming runs and halt interrupts the
+	// runs being executed; both are set by Start.
+	draining   atomic.Bool
+	stopIntake context.CancelFunc
+	halt       context.CancelCauseFunc
+
+	// stopping is closed once shutdown begins, before open requests are
+	// waited for, so that requests waiting on runs give up.
+	stopping chan struct{}
+	stopOnce sync.Once
+
+	// waiters are woken up when a run finishes on this instance, and events
+	// carries the news to the run's subscribers.
+	waiters *waiters
+	events  EventBus
+
+	// running holds the cancel func of every run currently being executed
+	// by a worker on this instance, keyed by run ID.
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+}
+
+// NewService creates a RunService consuming the given queue with the
+// configured number of workers. Claims on runs being processed are extended
+// three times per visibility timeout, and run leases, held under instanceID,
+// three times per lease duration.
+func NewService(logger *zap.Logger, conf config.Queue, lease config.Lease, scheduler config.Scheduler, instanceID string, queue Queue, runRepo RunRepository, stepRunRepo StepRunRepository, executor Executor, events EventBus, slack slack.Sender) *RunService {
 	return &RunService{
-		logger:   logger,
-		runRepo:  runRepo,
-		executor: executor,
-		queue:    queue,
-		workers:  conf.Workers,
-		slack:    slack,
+		logger:        logger,
+		runRepo:       runRepo,
+		stepRunRepo:   stepRunRepo,
+		executor:      executor,
+		queue:         queue,
+		workers:       conf.Workers,
+		extendEvery:   helpers.Seconds(conf.VisibilityTimeout) / 3,
+		slack:         slack,
+		instanceID:    instanceID,
+		leaseDuration: helpers.Seconds(lease.Duration),
+		sweepInterval: helpers.Seconds(lease.SweepInterval),
+		schedulePoll:  helpers.Seconds(scheduler.PollInterval),
+		stopping:      make(chan struct{}),
+		waiters:       newWaiters(),
+		events:        events,
+		running:       make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Create persists a new run of the named flow and enqueues it for processing.
+// A run whose start time is in the future is SCHEDULED instead, and queued
+// by the scheduler once it is due. An empty flow name selects the default
+// flow; unknown flows return NotFound.
+//
+// If opts carry an idempotency key already used by an identical request, the
+// run that request created is returned instead and the returned bool is
+// false. A key used by a different request returns Conflict.
+func (s *RunService) Create(ctx context.Context, flowName string, input map[string]any, opts models.CreateOptions) (*models.Run, bool, error) {
+	if err := s.accepting(); err != nil {
+		return nil, false, err
+	}
+	if flowName == "" {
+		flowName = s.executor.DefaultFlow()
+	}
+	if _, err := flow.Get(flowName); err != nil {
+		return nil, false, err
+	}
+
+	run := newRun(uuid.New().String(), flowName, input)
+	now := time.Now().UTC()
+	startAt := opts.StartTime(now)
+	if startAt.After(now) {
+		run.Status = models.StatusScheduled
+		run.RunAt = &startAt
+		run.PendingSince = nil
+	}
+	if timeout := opts.TimeoutDuration(); timeout > 0 {
+		run.Deadline = helpers.FormatDateTime(startAt.Add(timeout))
+	}
+	if opts.IdempotencyKey != "" {
+		run.IdempotencyKey = opts.IdempotencyKey
+		run.RequestHash = opts.Fingerprint(flowName, input)
+		if prev, err := s.existing(ctx, run); prev != nil || err != nil {
+			return prev, false, err
+		}
+	}
+
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		// A concurrent request with the same key may have created it first.
+		if run.IdempotencyKey != "" && isKind(err, errors.Conflict) {
+			if prev, err := s.existing(ctx, run); prev != nil || err != nil {
+				return prev, false, err
+			}
+		}
+		s.logger.Error("Failed To Create Run", zap.Error(err))
+		return nil, false, err
+	}
+	if run.Status == models.StatusScheduled {
+		s.logger.Info("Run Scheduled", zap.String("runId", run.ID), zap.Time("runAt", startAt))
+		return &run, true, nil
+	}
+
+	// A run that could not be queued stays PENDING and is queued by the sweep.
+	if err := s.enqueue(ctx, run.ID); err != nil {
+		return nil, false, err
+	}
+	return &run, true, nil
+}
+
+// existing returns the run created earlier with run's idempotency key, or
+// nil if there is none. It returns Conflict if that run was created by a
+// request different from the one creating run.
+func (s *RunService) existing(ctx context.Context, run models.Run) (*models.Run, error) {
+	prev, err := s.runRepo.GetByIdempotencyKey(ctx, run.IdempotencyKey)
+	if err != nil {
+		if isKind(err, errors.NotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if prev.RequestHash != run.RequestHash {
+		return nil, errors.E(errors.Conflict, "idempotency key was already used for a different request")
 	}
+
+	s.logger.Info("Run Already Created With Idempotency Key", zap.String("runId", prev.ID))
+	return prev, nil
 }
 
-// Create persists a new run and enqueues it for processing.
-func (s *RunService) Create(ctx context.Context, input map[string]any) (string, error) {
-	run := models.Run{
-		ID:             uuid.New().String(),
+// Trigger creates and enqueues a run of the named flow on behalf of a
+// schedule. The caller derives runID from the tick being fired, so that
+// firing a tick again, e.g. from another instance, creates no second run:
+// Trigger then returns false.
+func (s *RunService) Trigger(ctx context.Context, runID, schedule, flowName string, input map[string]any) (bool, error) {
+	if err := s.accepting(); err != nil {
+		return false, err
+	}
+	if _, err := flow.Get(flowName); err != nil {
+		return false, err
+	}
+
+	run := newRun(runID, flowName, input)
+	run.Schedule = schedule
+	if err := s.runRepo.Create(ctx, run); err != nil {
+		if isKind(err, errors.Conflict) {
+			return false, nil
+		}
+		s.logger.Error("Failed To Create Run", zap.String("schedule", schedule), zap.Error(err))
+		return false, err
+	}
+
+	// A run that could not be queued stays PENDING and is queued by the sweep.
+	return true, s.enqueue(ctx, run.ID)
+}
+
+// newRun returns a PENDING run of the named flow.
+func newRun(runID, flowName string, input map[string]any) models.Run {
+	now := time.Now().UTC()
+	return models.Run{
+		ID:             runID,
+		Flow:           flowName,
 		CreatedAt:      helpers.GetCurrentDateTime(),
 		Input:          input,
+		Status:         models.StatusPending,
 		IsCompleted:    false,
 		CompletedAt:    helpers.GetNotEndedTime(),
 		LastStepStatus: false,
+		PendingSince:   &now,
 	}
+}
 
-	if err := s.runRepo.Create(ctx, run); err != nil {
-		s.logger.Error("Failed To Create Run", zap.Error(err))
-		return "", err
+// Subscribe returns a channel receiving the events of a run executing on
+// this instance from now on, and a func that unsubscribes. The channel is
+// closed if the subscriber falls too far behind.
+func (s *RunService) Subscribe(runID string) (<-chan evmodels.Event, func()) {
+	return s.events.Subscribe(runID)
+}
+
+// finished announces that a run reached a terminal status: its waiters are
+// woken up and the event is published to its subscribers.
+func (s *RunService) finished(event evmodels.Event) {
+	s.waiters.notify(event.RunID)
+	s.events.Publish(event)
+}
+
+// Get returns a run along with every step run recorded for it, in flow
+// order, see sortSteps.
+func (s *RunService) Get(ctx context.Context, runID string) (*models.Details, error) {
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.stepRunRepo.GetByRunID(ctx, runID)
+	if err != nil {
+		s.logger.Error("Failed To Fetch Step Runs", zap.String("runId", runID), zap.Error(err))
+		return nil, err
 	}
 
-	s.enqueue(run)
-	return run.ID, nil
+	return &models.Details{Run: *run, Steps: s.sortSteps(*run, steps)}, nil
 }
 
-// Start spawns workers and re-enqueues any incomplete runs from the database.
-// Call this once during server initialization.
+// sortSteps orders a run's step runs like the steps of its flow, every step
+// after the steps it depends on. Step runs of steps no longer in the flow,
+// or of a flow no longer registered, come last in the order they started.
+func (s *RunService) sortSteps(run models.Run, steps []srmodels.StepRun) []srmodels.StepRun {
+	name := run.Flow
+	if name == "" {
+		name = s.executor.DefaultFlow()
+	}
+	f, err := flow.Get(name)
+	if err != nil {
+		return steps
+	}
+
+	position := make(map[string]int)
+	for i, node := range f.SortedNodes() {
+		position[node.Name] = i
+	}
+	slices.SortStableFunc(steps, func(a, b srmodels.StepRun) int {
+		pa, okA := position[a.ID.StepName]
+		pb, okB := position[b.ID.StepName]
+		switch {
+		case okA && okB:
+			return pa - pb
+		case okA:
+			return -1
+		case okB:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return steps
+}
+
+// List returns a page of runs matching the query along with the cursor
+// for the next page.
+func (s *RunService) List(ctx context.Context, query models.ListQuery) (*models.ListResult, error) {
+	runs, next, err := s.runRepo.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListResult{Runs: runs, NextCursor: next}, nil
+}
+
+// Cancel stops a run. A queued run is marked CANCELLED and skipped when a
+// worker picks it up; a running run additionally has its context cancelled,
+// which interrupts the step currently executing.
+func (s *RunService) Cancel(ctx context.Context, runID string) error {
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status.IsTerminal() {
+		return errors.E(errors.Invalid, fmt.Sprintf("run is already %s", run.Status))
+	}
+	if run.Status == models.StatusCompensating {
+		return errors.E(errors.Invalid, "run is being compensated and cannot be cancelled")
+	}
+
+	ok, err := s.runRepo.MarkCancelled(ctx, runID, errCancelled.Error())
+	if err != nil {
+		s.logger.Error("Failed To Mark Run As Cancelled", zap.String("runId", runID), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return errors.E(errors.Invalid, "run has already finished")
+	}
+
+	s.mu.Lock()
+	cancel, isRunning := s.running[runID]
+	s.mu.Unlock()
+	if isRunning {
+		cancel(errCancelled)
+	}
+	s.finished(evmodels.Event{Type: evmodels.RunCancelled, RunID: runID, Reason: errCancelled.Error()})
+
+	s.logger.Info("Run Cancelled", zap.String("runId", runID), zap.Bool("wasRunning", isRunning))
+	return nil
+}
+
+// Retry re-enqueues a FAILED or CANCELLED run. The executor resumes it from
+// the step that did not complete, using the input that step was recorded with.
+// Retrying (or rerunning) a run clears its deadline.
+func (s *RunService) Retry(ctx context.Context, runID string) error {
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.Status != models.StatusFailed && run.Status != models.StatusCancelled {
+		return errors.E(errors.Invalid, fmt.Sprintf("only FAILED or CANCELLED runs can be retried, run is %s", run.Status))
+	}
+
+	retryable := []models.Status{models.StatusFailed, models.StatusCancelled}
+	return s.requeue(ctx, *run, retryable)
+}
+
+// Rerun re-enqueues a finished run so that it executes again starting from the
+// given step, using the input that step was recorded with. Steps executed
+// previously keep their earlier executions in their history.
+func (s *RunService) Rerun(ctx context.Context, runID, fromStep string) error {
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if !run.Status.IsTerminal() {
+		return errors.E(errors.Invalid, fmt.Sprintf("run is still %s", run.Status))
+	}
+
+	if err = s.executor.PrepareRerun(ctx, *run, fromStep); err != nil {
+		return err
+	}
+
+	terminal := []models.Status{models.StatusCompleted, models.StatusFailed, models.StatusCancelled}
+	return s.requeue(ctx, *run, terminal)
+}
+
+// requeue moves a run back to PENDING and pushes it onto the queue.
+func (s *RunService) requeue(ctx context.Context, run models.Run, from []models.Status) error {
+	if err := s.accepting(); err != nil {
+		return err
+	}
+
+	ok, err := s.runRepo.Reopen(ctx, run.ID, from)
+	if err != nil {
+		s.logger.Error("Failed To Reopen Run", zap.String("runId", run.ID), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return errors.E(errors.Invalid, "run status changed, try again")
+	}
+
+	return s.enqueue(ctx, run.ID)
+}
+
+// withDeadline bounds a run's context by the run's deadline, if it has one.
+func withDeadline(ctx context.Context, run models.Run) (context.Context, context.CancelFunc) {
+	if run.Deadline == "" {
+		return ctx, func() {}
+	}
+
+	deadline, err := helpers.ParseDateTime(run.Deadline)
+	if err != nil {
+		return ctx, func() {}
+	}
+	return context.WithDeadlineCause(ctx, deadline, errDeadlineExceeded)
+}
+
+// track registers a cancellable context for a run being executed and returns
+// it along with a func that unregisters it once execution ends.
+func (s *RunService) track(ctx context.Context, runID string) (context.Context, func()) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+
+	s.mu.Lock()
+	s.running[runID] = cancel
+	s.mu.Unlock()
+
+	return runCtx, func() {
+		s.mu.Lock()
+		delete(s.running, runID)
+		s.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// Start spawns workers and the lease sweeper, and re-enqueues any incomplete
+// runs from the database. Runs already in a durable queue are left as they
+// are, so this only picks up runs that never made it into the queue (or were
+// queued in memory). Call this once during server initialization.
+//
+// Workers keep running after ctx is done; call Shutdown to stop them. Callers
+// waiting on runs give up once ctx is done, see Stopping.
 func (s *RunService) Start(ctx context.Context) error {
+	workCtx, halt := context.WithCancelCause(context.WithoutCancel(ctx))
+	intakeCtx, stopIntake := context.WithCancel(workCtx)
+	s.halt, s.stopIntake = halt, stopIntake
+
+	go func() {
+		<-ctx.Done()
+		s.stop()
+	}()
+
 	for i := 0; i < s.workers; i++ {
 		s.wg.Add(1)
-		go s.worker(ctx, i)
+		go s.worker(intakeCtx, workCtx, i)
 		helpers.Sleep100MS()
 	}
 
+	s.wg.Add(1)
+	go s.sweep(intakeCtx)
+
+	s.wg.Add(1)
+	go s.schedule(intakeCtx)
+
 	incomplete, err := s.runRepo.GetIncomplete(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load incomplete runs: %w", err)
 	}
 
 	for _, run := range incomplete {
-		s.enqueue(run)
+		if err = s.enqueue(ctx, run.ID); err != nil {
+			return fmt.Errorf("failed to re-enqueue run %s: %w", run.ID, err)
+		}
 	}
 
 	return nil
 }
 
-// enqueue pushes a run onto the buffered queue for worker consumption.
-func (s *RunService) enqueue(run models.Run) {
-	s.queue <- run
-	s.logger.Info("Run Enqueued", zap.String("runId", run.ID))
+// Shutdown drains the service: new runs are refused, workers stop claiming
+// runs and every run being executed stops starting new steps. Runs with steps
+// left are paused once their executing steps finish, and are resumed later by
+// this or another instance. If ctx is done before every worker has stopped,
+// the executing steps are interrupted and ctx's error is returned. Runs still
+// queued are left in the queue.
+func (s *RunService) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+	s.stop()
+	s.executor.Drain()
+	s.stopIntake()
+
+	s.mu.Lock()
+	inFlight := len(s.running)
+	s.mu.Unlock()
+	s.logger.Info("Draining Runs", zap.Int("inFlight", inFlight))
+
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		s.logger.Info("Runs Drained")
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	inFlight = len(s.running)
+	s.mu.Unlock()
+	s.logger.Warn("Drain Timeout Reached, Interrupting Runs", zap.Int("inFlight", inFlight))
+
+	s.halt(errShutdown)
+	<-stopped
+	return ctx.Err()
+}
+
+// Stopping returns a channel closed once the service starts shutting down,
+// i.e. once the context passed to Start is done or Shutdown is called.
+// Requests waiting on runs should give up then, so that they do not hold up
+// the server's shutdown.
+func (s *RunService) Stopping() <-chan struct{} {
+	return s.stopping
+}
+
+// stop closes the stopping channel, if it is not closed yet.
+func (s *RunService) stop() {
+	s.stopOnce.Do(func() { close(s.stopping) })
 }
 
-// worker is a long-running goroutine that pulls runs from the queue
-// and processes them sequentially (steps within a run are sequential,
-// but multiple runs across workers execute in parallel).
-func (s *RunService) worker(ctx context.Context, workerID int) {
+// accepting returns an Unavailable error once the service is shutting down.
+func (s *RunService) accepting() error {
+	if s.draining.Load() {
+		return errors.E(errors.Unavailable, "server is shutting down")
+	}
+	return nil
+}
+
+// enqueue pushes a run onto the queue for worker consumption.
+func (s *RunService) enqueue(ctx context.Context, runID string) error {
+	if err := s.queue.Push(ctx, runID); err != nil {
+		s.logger.Error("Failed To Enqueue Run", zap.String("runId", runID), zap.Error(err))
+		return err
+	}
+	s.logger.Info("Run Enqueued", zap.String("runId", runID))
+	return nil
+}
+
+// worker is a long-running goroutine that claims runs from the queue
+// and processes them one at a time (multiple runs across workers execute
+// in parallel). It claims runs until intake is done and executes them with
+// ctx. A run is acked once processed; the claim on a run interrupted by
+// shutdown is released instead, so that it is delivered again.
+func (s *RunService) worker(intake, ctx context.Context, workerID int) {
 	defer s.wg.Done()
 	s.logger.Info("Worker Started", zap.Int("workerId", workerID))
 
+	for {
+		item, err := s.queue.Pop(intake)
+		if err != nil {
+			if intake.Err() != nil {
+				s.logger.Info("Worker Shutting Down", zap.Int("workerId", workerID))
+				return
+			}
+			s.logger.Error("Failed To Claim Run", zap.Int("workerId", workerID), zap.Error(err))
+			helpers.SleepOneSecond()
+			continue
+		}
+
+		s.handle(ctx, workerID, item)
+	}
+}
+
+// handle loads a claimed run and processes it, keeping the claim alive while
+// it executes and acking it afterwards. The claim is released instead if the
+// run could not be taken up, so that it is delivered again.
+func (s *RunService) handle(ctx context.Context, workerID int, item *qmodels.Item) {
+	run, err := s.runRepo.Get(ctx, item.RunID)
+	if err != nil {
+		s.logger.Error("Failed To Load Claimed Run", zap.String("runId", item.RunID),
+			zap.Int("workerId", workerID), zap.Error(err))
+		// A run that no longer exists will never be processed.
+		if isKind(err, errors.NotFound) {
+			s.ack(ctx, workerID, item)
+		}
+		return
+	}
+
+	stopExtending := s.extendClaim(ctx, workerID, item)
+	processed := s.process(ctx, workerID, *run)
+	stopExtending()
+
+	if !processed || ctx.Err() != nil {
+		s.release(context.WithoutCancel(ctx), workerID, item)
+		return
+	}
+	s.ack(ctx, workerID, item)
+}
+
+// isKind reports whether err is an *errors.Error of the given kind.
+func isKind(err error, kind errors.Kind) bool {
+	var appErr *errors.Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
+
+// extendClaim periodically extends the claim on a run being processed, until
+// the returned func is called.
+func (s *RunService) extendClaim(ctx context.Context, workerID int, item *qmodels.Item) func() {
+	return every(ctx, s.extendEvery, func() {
+		if err := s.queue.Extend(ctx, item); err != nil {
+			s.logger.Error("Failed To Extend Claim On Run", zap.String("runId", item.RunID),
+				zap.Int("workerId", workerID), zap.Error(err))
+		}
+	})
+}
+
+// every calls fn every interval in a goroutine until ctx is done or the
+// returned func is called. The returned func waits for a call in progress.
+func every(ctx context.Context, interval time.Duration, fn func()) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// sweep periodically re-enqueues the runs whose lease expired, so that runs
+// abandoned by a dead instance are taken over without waiting for a restart.
+// It also re-enqueues the runs PENDING for a whole sweep interval, in case
+// queueing them failed; a run queued twice is only executed once.
+func (s *RunService) sweep(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.Info("Worker Shutting Down", zap.Int("workerId", workerID))
 			return
-		case run := <-s.queue:
-			err := s.executor.StartRun(ctx, workerID, run.ID, run.Input)
-			if err != nil {
-				alert := slack.Alert{
-					Title: "Exception In FlowX Service",
-					Fields: map[string]string{
-						"Message": "Run Execution Failed",
-						"RunID":   run.ID,
-						"Error":   err.Error(),
-					},
-				}
-				if alertErr := s.slack.Send(ctx, alert); alertErr != nil {
-					s.logger.Error("Failed To Send Alert", zap.String("runId", run.ID),
-						zap.Int("workerId", workerID), zap.Error(alertErr))
-				}
-				continue
+		case <-ticker.C:
+		}
+
+		orphaned, err := s.runRepo.GetOrphaned(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("Failed To Load Orphaned Runs", zap.Error(err))
 			}
+			continue
+		}
+		for _, run := range orphaned {
+			s.logger.Warn("Taking Over Orphaned Run", zap.String("runId", run.ID),
+				zap.String("previousOwner", run.Owner))
+			_ = s.enqueue(ctx, run.ID)
+		}
 
-			if err := s.runRepo.MarkComplete(ctx, run.ID); err != nil {
-				s.logger.Error("Failed To Mark Run As Complete", zap.String("runId", run.ID),
-					zap.Int("workerId", workerID), zap.Error(err))
-				continue
+		stale, err := s.runRepo.GetStalePending(ctx, time.Now().UTC().Add(-s.sweepInterval))
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("Failed To Load Stale Pending Runs", zap.Error(err))
 			}
+			continue
+		}
+		for _, run := range stale {
+			s.logger.Info("Queueing Stale Pending Run", zap.String("runId", run.ID))
+			_ = s.enqueue(ctx, run.ID)
+		}
+	}
+}
 
-			s.logger.Info("Run Completed", zap.String("runId", run.ID),
+// ack removes a processed run from the queue.
+func (s *RunService) ack(ctx context.Context, workerID int, item *qmodels.Item) {
+	if err := s.queue.Ack(ctx, item); err != nil {
+		s.logger.Error("Failed To Ack Run", zap.String("runId", item.RunID),
+			zap.Int("workerId", workerID), zap.Error(err))
+	}
+}
+
+// release gives up the claim on a run that was not processed, so that it is
+// delivered again right away.
+func (s *RunService) release(ctx context.Context, workerID int, item *qmodels.Item) {
+	if err := s.queue.Release(ctx, item); err != nil {
+		s.logger.Error("Failed To Release Run", zap.String("runId", item.RunID),
+			zap.Int("workerId", workerID), zap.Error(err))
+	}
+}
+
+// process executes a single run while holding its lease, so that no other
+// instance executes it at the same time. The lease is renewed until the run
+// is done and then released; if renewing fails, execution is stopped since
+// another instance may have taken the run over, or the run was cancelled
+// through another instance. It returns false if the run could not be taken
+// up because of an error, and should be processed again.
+func (s *RunService) process(ctx context.Context, workerID int, run models.Run) bool {
+	ok, err := s.runRepo.AcquireLease(ctx, run.ID, s.instanceID, s.leaseDuration)
+	if err != nil {
+		s.logger.Error("Failed To Acquire Run Lease", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID), zap.Error(err))
+		return false
+	}
+	if !ok {
+		s.logger.Info("Skipping Run Leased By Another Instance", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID))
+		return true
+	}
+	defer func() {
+		if err := s.runRepo.ReleaseLease(context.WithoutCancel(ctx), run.ID, s.instanceID); err != nil {
+			s.logger.Error("Failed To Release Run Lease", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID), zap.Error(err))
+		}
+	}()
+
+	leaseCtx, loseLease := context.WithCancelCause(ctx)
+	defer loseLease(nil)
+
+	stopRenewing := every(leaseCtx, s.leaseDuration/3, func() {
+		ok, err := s.runRepo.RenewLease(leaseCtx, run.ID, s.instanceID, s.leaseDuration)
+		if err != nil {
+			s.logger.Error("Failed To Renew Run Lease", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID), zap.Error(err))
+			return
+		}
+		if !ok {
+			loseLease(s.leaseLostCause(leaseCtx, workerID, run.ID))
+		}
+	})
+	defer stopRenewing()
+
+	return s.execute(leaseCtx, workerID, run)
+}
+
+// leaseLostCause tells why renewing the lease on a run being executed failed,
+// and returns the cause to stop its execution with. A run that has just
+// finished on this instance is not worth a warning.
+func (s *RunService) leaseLostCause(ctx context.Context, workerID int, runID string) error {
+	run, err := s.runRepo.Get(ctx, runID)
+	if err == nil && run.Status == models.StatusCancelled {
+		s.logger.Info("Run Cancelled Elsewhere, Stopping Execution", zap.String("runId", runID),
+			zap.Int("workerId", workerID))
+		return errCancelled
+	}
+	if err == nil && run.Status.IsTerminal() {
+		return errLeaseLost
+	}
+
+	s.logger.Warn("Run Lease Lost, Stopping Execution", zap.String("runId", runID),
+		zap.Int("workerId", workerID))
+	return errLeaseLost
+}
+
+// execute runs a leased run and moves it into its resulting status.
+// Runs paused or interrupted by shutdown are left RUNNING so that recovery
+// resumes them, runs cancelled while queued are skipped, and runs whose
+// deadline passes are failed. Recovered COMPENSATING runs resume their compensation.
+// It returns false if the run could not be marked as running.
+func (s *RunService) execute(ctx context.Context, workerID int, run models.Run) bool {
+	if run.Status == models.StatusCompensating {
+		s.fail(ctx, workerID, run, run.Reason)
+		return true
+	}
+
+	// Register before marking the run as running so that a concurrent Cancel
+	// either stops MarkRunning from matching or finds the cancel func.
+	runCtx, done := s.track(ctx, run.ID)
+	defer done()
+
+	runCtx, stop := withDeadline(runCtx, run)
+	defer stop()
+
+	ok, err := s.runRepo.MarkRunning(ctx, run.ID)
+	if err != nil {
+		s.logger.Error("Failed To Mark Run As Running", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID), zap.Error(err))
+		return false
+	}
+	if !ok {
+		s.logger.Info("Skipping Run No Longer Runnable", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID))
+		return true
+	}
+
+	// A run picked up after its deadline is failed without executing a step.
+	var output map[string]any
+	err = context.Cause(runCtx)
+	if err == nil {
+		output, err = s.executor.StartRun(runCtx, workerID, run)
+	}
+	if err != nil {
+		if errors.Is(context.Cause(ctx), errLeaseLost) {
+			s.logger.Warn("Run Abandoned After Losing Its Lease", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID))
+			return true
+		}
+		if errors.Is(context.Cause(ctx), errCancelled) {
+			s.logger.Info("Run Execution Stopped After Cancel", zap.String("runId", run.ID),
 				zap.Int("workerId", workerID))
+			return true
 		}
+		if ctx.Err() != nil {
+			s.logger.Warn("Run Interrupted By Shutdown", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID))
+			return true
+		}
+		if isKind(err, errors.Unavailable) {
+			s.logger.Info("Run Paused For Shutdown", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID))
+			return true
+		}
+
+		reason := err.Error()
+		switch {
+		case errors.Is(context.Cause(runCtx), errDeadlineExceeded):
+			err = errDeadlineExceeded
+			reason = srmodels.FailureReason(srmodels.FailureTimedOut, err)
+			s.logger.Warn("Run Deadline Exceeded", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID))
+		case runCtx.Err() != nil:
+			s.logger.Info("Run Execution Stopped After Cancel", zap.String("runId", run.ID),
+				zap.Int("workerId", workerID))
+			return true
+		}
+
+		s.fail(ctx, workerID, run, reason)
+		return true
+	}
+
+	ok, err = s.runRepo.MarkComplete(ctx, run.ID, output)
+	if err != nil {
+		s.logger.Error("Failed To Mark Run As Complete", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID), zap.Error(err))
+		return true
+	}
+	if !ok {
+		s.logger.Info("Skipping Completion Of Run Already Finished", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID))
+		return true
+	}
+	s.finished(evmodels.Event{Type: evmodels.RunCompleted, RunID: run.ID, Output: output})
+
+	s.logger.Info("Run Completed", zap.String("runId", run.ID),
+		zap.Int("workerId", workerID))
+	return true
+}
+
+// fail moves a run whose execution failed into FAILED and raises an alert.
+// If the run's flow defines compensations, the run is COMPENSATING while its
+// completed steps are compensated first; shutdown leaves it in that status so
+// that recovery resumes the compensation.
+func (s *RunService) fail(ctx context.Context, workerID int, run models.Run, reason string) {
+	if s.executor.HasCompensation(run) {
+		if run.Status != models.StatusCompensating {
+			ok, err := s.runRepo.MarkCompensating(ctx, run.ID, reason)
+			if err != nil {
+				s.logger.Error("Failed To Mark Run As Compensating", zap.String("runId", run.ID),
+					zap.Int("workerId", workerID), zap.Error(err))
+				return
+			}
+			if !ok {
+				s.logger.Info("Skipping Compensation Of Run No Longer Running", zap.String("runId", run.ID),
+					zap.Int("workerId", workerID))
+				return
+			}
+		}
+
+		if err := s.executor.Compensate(ctx, workerID, run); err != nil {
+			if ctx.Err() != nil {
+				s.logger.Warn("Compensation Interrupted By Shutdown", zap.String("runId", run.ID),
+					zap.Int("workerId", workerID))
+				return
+			}
+			reason = fmt.Sprintf("%s; compensation failed: %v", reason, err)
+		}
+	}
+
+	if ok, markErr := s.runRepo.MarkFailed(ctx, run.ID, reason); markErr != nil {
+		s.logger.Error("Failed To Mark Run As Failed", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID), zap.Error(markErr))
+	} else if ok {
+		s.finished(evmodels.Event{Type: evmodels.RunFailed, RunID: run.ID, Reason: reason})
+	}
+
+	alert := slack.Alert{
+		Title: "Exception In FlowX Service",
+		Fields: map[string]string{
+			"Message": "Run Execution Failed",
+			"RunID":   run.ID,
+			"Error":   reason,
+		},
+	}
+	if alertErr := s.slack.Send(ctx, alert); alertErr != nil {
+		s.logger.Error("Failed To Send Alert", zap.String("runId", run.ID),
+			zap.Int("workerId", workerID), zap.Error(alertErr))
 	}
 }
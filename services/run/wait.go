@@ -0,0 +1,95 @@
+package run
+
+import (
+	// Go Internal Packages
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	// Local Packages
+	models "flowx/models/run"
+)
+
+// waiters tracks the callers waiting for runs to finish, keyed by run ID.
+type waiters struct {
+	mu    sync.Mutex
+	chans map[string][]chan struct{}
+}
+
+func newWaiters() *waiters {
+	return &waiters{chans: make(map[string][]chan struct{})}
+}
+
+// add registers a waiter for a run. The returned channel is closed once the
+// run finishes, and the returned func unregisters the waiter.
+func (w *waiters) add(runID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+
+	w.mu.Lock()
+	w.chans[runID] = append(w.chans[runID], ch)
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.chans[runID] = slices.DeleteFunc(w.chans[runID], func(c chan struct{}) bool { return c == ch })
+		if len(w.chans[runID]) == 0 {
+			delete(w.chans, runID)
+		}
+	}
+}
+
+// notify wakes up every waiter of a run that just finished.
+func (w *waiters) notify(runID string) {
+	w.mu.Lock()
+	chans := w.chans[runID]
+	delete(w.chans, runID)
+	w.mu.Unlock()
+
+	for _, ch := range chans {
+		close(ch)
+	}
+}
+
+// waitRecheckInterval is how often Wait checks the status of a run, which it
+// is not told about if the run executes on another instance.
+const waitRecheckInterval = 5 * time.Second
+
+// Wait blocks until a run reaches a terminal status, and returns it. It gives
+// up once timeout passes or the service starts shutting down, and returns the
+// run as it is then. A run finishing on this instance wakes Wait up at once;
+// one executed by another instance is noticed by checking its status every
+// waitRecheckInterval.
+func (s *RunService) Wait(ctx context.Context, runID string, timeout time.Duration) (*models.Run, error) {
+	// Register before loading the run so that it cannot finish unnoticed.
+	finished, stop := s.waiters.add(runID)
+	defer stop()
+
+	run, err := s.runRepo.Get(ctx, runID)
+	if err != nil || run.Status.IsTerminal() {
+		return run, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	recheck := time.NewTicker(waitRecheckInterval)
+	defer recheck.Stop()
+
+	for {
+		select {
+		case <-finished:
+		case <-timer.C:
+		case <-s.stopping:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-recheck.C:
+			run, err := s.runRepo.Get(ctx, runID)
+			if err != nil || run.Status.IsTerminal() {
+				return run, err
+			}
+			continue
+		}
+		return s.runRepo.Get(ctx, runID)
+	}
+}
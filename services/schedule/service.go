@@ -0,0 +1,301 @@
+package schedule
+
+import (
+	// Go Internal Packages
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	// Local Packages
+	config "flowx/config"
+	errors "flowx/errors"
+	flow "flowx/flow"
+	models "flowx/models/schedule"
+	cron "flowx/utils/cron"
+	helpers "flowx/utils/helpers"
+
+	// External Packages
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ScheduleRepository defines the persistence operations the service needs.
+type ScheduleRepository interface {
+	Create(ctx context.Context, schedule models.Schedule) error
+	Sync(ctx context.Context, def models.Definition, nextRunAt time.Time, createdAt string) error
+	Prune(ctx context.Context, keep []string) error
+	Get(ctx context.Context, name string) (*models.Schedule, error)
+	List(ctx context.Context) ([]models.Schedule, error)
+	Delete(ctx context.Context, name string) error
+	Pause(ctx context.Context, name string) error
+	Resume(ctx context.Context, name string, nextRunAt time.Time) error
+	GetDue(ctx context.Context, now time.Time) ([]models.Schedule, error)
+	Advance(ctx context.Context, name string, from, next time.Time, ranAt *time.Time, runID string) (bool, error)
+}
+
+// RunTrigger creates the runs of the ticks being fired.
+type RunTrigger interface {
+	Trigger(ctx context.Context, runID, schedule, flowName string, input map[string]any) (bool, error)
+}
+
+// ScheduleService manages cron schedules and fires them. Every instance
+// checks for due schedules; the run of a tick has an ID derived from the
+// schedule and the tick, so instances firing the same tick create it once.
+type ScheduleService struct {
+	logger       *zap.Logger
+	repo         ScheduleRepository
+	runs         RunTrigger
+	pollInterval time.Duration
+
+	stop func()
+	wg   sync.WaitGroup
+}
+
+// NewService creates a ScheduleService checking for due schedules every
+// poll interval.
+func NewService(logger *zap.Logger, conf config.Scheduler, repo ScheduleRepository, runs RunTrigger) *ScheduleService {
+	return &ScheduleService{
+		logger:       logger,
+		repo:         repo,
+		runs:         runs,
+		pollInterval: helpers.Seconds(conf.PollInterval),
+		stop:         func() {},
+	}
+}
+
+// Sync stores the schedules defined in the config file, and deletes the
+// ones that were removed from it.
+func (s *ScheduleService) Sync(ctx context.Context, defs []models.Definition) error {
+	now := time.Now().UTC()
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		names = append(names, def.Name)
+		def.SetDefaults()
+		c, err := cron.Parse(def.Cron)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Sync(ctx, def, c.Next(now), helpers.GetCurrentDateTime()); err != nil {
+			return fmt.Errorf("failed to sync schedule %s: %w", def.Name, err)
+		}
+	}
+	return s.repo.Prune(ctx, names)
+}
+
+// Create validates and stores a new schedule, which fires first on the next
+// tick of its cron expression.
+func (s *ScheduleService) Create(ctx context.Context, def models.Definition) (*models.Schedule, error) {
+	def.SetDefaults()
+	ve := errors.ValidationErrs()
+	def.Validate(ve, "")
+	if def.Flow != "" && !flow.Exists(def.Flow) {
+		ve.Add("flow", fmt.Sprintf("%s not found in registry", def.Flow))
+	}
+	if err := ve.Err(); err != nil {
+		return nil, errors.ValidationFailedErr(err)
+	}
+
+	c, _ := cron.Parse(def.Cron)
+	schedule := models.Schedule{
+		Definition: def,
+		Source:     models.SourceAPI,
+		NextRunAt:  c.Next(time.Now()),
+		CreatedAt:  helpers.GetCurrentDateTime(),
+	}
+	if err := s.repo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Schedule Created", zap.String("schedule", def.Name), zap.String("cron", def.Cron))
+	return &schedule, nil
+}
+
+// Get returns the schedule with the given name.
+func (s *ScheduleService) Get(ctx context.Context, name string) (*models.Schedule, error) {
+	return s.repo.Get(ctx, name)
+}
+
+// List returns every schedule.
+func (s *ScheduleService) List(ctx context.Context) ([]models.Schedule, error) {
+	return s.repo.List(ctx)
+}
+
+// Delete removes a schedule created through the API. Schedules defined in
+// the config file are synced again on startup, so they cannot be deleted.
+func (s *ScheduleService) Delete(ctx context.Context, name string) error {
+	schedule, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if schedule.Source == models.SourceConfig {
+		return errors.E(errors.Invalid, "schedule is defined in the config file, remove it there")
+	}
+
+	if err := s.repo.Delete(ctx, name); err != nil {
+		return err
+	}
+	s.logger.Info("Schedule Deleted", zap.String("schedule", name))
+	return nil
+}
+
+// Pause stops a schedule from firing until it is resumed.
+func (s *ScheduleService) Pause(ctx context.Context, name string) error {
+	if err := s.repo.Pause(ctx, name); err != nil {
+		return err
+	}
+	s.logger.Info("Schedule Paused", zap.String("schedule", name))
+	return nil
+}
+
+// Resume lets a paused schedule fire again from its next tick. Ticks missed
+// while it was paused are not caught up.
+func (s *ScheduleService) Resume(ctx context.Context, name string) error {
+	schedule, err := s.repo.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !schedule.Paused {
+		return errors.E(errors.Invalid, "schedule is not paused")
+	}
+
+	c, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Resume(ctx, name, c.Next(time.Now())); err != nil {
+		return err
+	}
+	s.logger.Info("Schedule Resumed", zap.String("schedule", name))
+	return nil
+}
+
+// Start spawns the goroutine firing due schedules until ctx is done or Stop
+// is called.
+func (s *ScheduleService) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.stop = cancel
+
+	s.wg.Add(1)
+	go s.loop(ctx)
+}
+
+// Stop stops firing schedules and waits for a tick being fired.
+func (s *ScheduleService) Stop() {
+	s.stop()
+	s.wg.Wait()
+}
+
+func (s *ScheduleService) loop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now().UTC()
+		due, err := s.repo.GetDue(ctx, now)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("Failed To Load Due Schedules", zap.Error(err))
+			}
+			continue
+		}
+		for _, schedule := range due {
+			s.fire(ctx, schedule, now)
+		}
+	}
+}
+
+// fire creates the runs a due schedule owes as of now according to its
+// catch-up policy, and advances it to its next tick. It stops when another
+// instance advanced the schedule first.
+func (s *ScheduleService) fire(ctx context.Context, schedule models.Schedule, now time.Time) {
+	c, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		s.logger.Error("Invalid Schedule", zap.String("schedule", schedule.Name), zap.Error(err))
+		return
+	}
+
+	for tick := schedule.NextRunAt; !tick.IsZero() && !tick.After(now); {
+		// Ticks missed before the latest one are only fired by CatchUpAll,
+		// and CatchUpNone fires the latest one only if it is barely late.
+		fireAt := tick
+		if schedule.CatchUp != models.CatchUpAll {
+			for next := c.Next(fireAt); !next.IsZero() && !next.After(now); next = c.Next(next) {
+				fireAt = next
+			}
+		}
+		skip := schedule.CatchUp == models.CatchUpNone && now.Sub(fireAt) > s.pollInterval+time.Minute
+
+		var ranAt *time.Time
+		runID := ""
+		if skip {
+			s.logger.Info("Skipping Missed Schedule Ticks", zap.String("schedule", schedule.Name),
+				zap.Time("latestTick", fireAt))
+		} else {
+			runID, err = s.trigger(ctx, schedule, fireAt)
+			if err != nil {
+				return
+			}
+			if runID != "" {
+				ranAt = &fireAt
+			}
+		}
+
+		next := c.Next(fireAt)
+		ok, err := s.repo.Advance(ctx, schedule.Name, tick, next, ranAt, runID)
+		if err != nil {
+			s.logger.Error("Failed To Advance Schedule", zap.String("schedule", schedule.Name), zap.Error(err))
+			return
+		}
+		if !ok {
+			return
+		}
+		tick = next
+	}
+}
+
+// trigger creates the run of a schedule's tick and returns its ID. It
+// returns an empty ID if the tick cannot create a run, e.g. because the flow
+// was removed, so that the schedule moves on; and an error if it should be
+// fired again later.
+func (s *ScheduleService) trigger(ctx context.Context, schedule models.Schedule, tick time.Time) (string, error) {
+	logger := s.logger.With(zap.String("schedule", schedule.Name), zap.Time("tick", tick))
+
+	input, err := models.RenderInput(schedule.Input, models.NewTickData(schedule.Name, tick))
+	if err != nil {
+		logger.Error("Failed To Render Schedule Input, Skipping Tick", zap.Error(err))
+		return "", nil
+	}
+
+	runID := tickRunID(schedule.Name, tick)
+	created, err := s.runs.Trigger(ctx, runID, schedule.Name, schedule.Flow, input)
+	if err != nil {
+		var appErr *errors.Error
+		if errors.As(err, &appErr) && appErr.Kind == errors.NotFound {
+			logger.Error("Schedule Flow Not Found, Skipping Tick", zap.Error(err))
+			return "", nil
+		}
+		if ctx.Err() == nil {
+			logger.Error("Failed To Trigger Scheduled Run", zap.Error(err))
+		}
+		return "", err
+	}
+
+	if created {
+		logger.Info("Scheduled Run Triggered", zap.String("runId", runID))
+	}
+	return runID, nil
+}
+
+// tickRunID derives the ID of the run created for a tick of a schedule.
+func tickRunID(name string, tick time.Time) string {
+	key := fmt.Sprintf("flowx/schedules/%s/%s", name, tick.UTC().Format(time.RFC3339))
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(key)).String()
+}
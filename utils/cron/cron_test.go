@@ -0,0 +1,164 @@
+package cron
+
+import (
+	// Go Internal Packages
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidExpressions(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "empty", expr: ""},
+		{name: "too few fields", expr: "* * * *"},
+		{name: "too many fields", expr: "* * * * * *"},
+		{name: "minute out of range", expr: "60 * * * *"},
+		{name: "day of month zero", expr: "0 0 0 * *"},
+		{name: "day of week out of range", expr: "0 0 * * 8"},
+		{name: "zero step", expr: "*/0 * * * *"},
+		{name: "reversed range", expr: "5-1 * * * *"},
+		{name: "not a number", expr: "a * * * *"},
+		{name: "unknown descriptor", expr: "@fortnightly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) succeeded, want an error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-03-22 is a Sunday.
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string // empty if the schedule never fires
+	}{
+		{
+			name: "every minute is strictly after from",
+			expr: "* * * * *",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-03-22T10:01:00Z",
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			from: "2026-03-22T10:07:30Z",
+			want: "2026-03-22T10:15:00Z",
+		},
+		{
+			name: "range with step",
+			expr: "0 9-17/4 * * *",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-03-22T13:00:00Z",
+		},
+		{
+			name: "list",
+			expr: "0,30 6,18 * * *",
+			from: "2026-03-22T06:30:00Z",
+			want: "2026-03-22T18:00:00Z",
+		},
+		{
+			name: "descriptor",
+			expr: "@hourly",
+			from: "2026-03-22T10:59:59Z",
+			want: "2026-03-22T11:00:00Z",
+		},
+		{
+			name: "sunday as 0",
+			expr: "0 0 * * 0",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-03-29T00:00:00Z",
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 0 * * 7",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-03-29T00:00:00Z",
+		},
+		{
+			name: "restricted day fields fire on either, day of week first",
+			expr: "0 0 1 * 1",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-03-23T00:00:00Z",
+		},
+		{
+			name: "restricted day fields fire on either, day of month first",
+			expr: "0 0 1 * 1",
+			from: "2026-03-30T12:00:00Z",
+			want: "2026-04-01T00:00:00Z",
+		},
+		{
+			name: "day of month starting with * must match both",
+			expr: "0 0 */2 * 1",
+			from: "2026-03-23T12:00:00Z",
+			want: "2026-04-13T00:00:00Z",
+		},
+		{
+			name: "day of week starting with * must match both",
+			expr: "0 0 13 * */7",
+			from: "2026-03-22T10:00:00Z",
+			want: "2026-09-13T00:00:00Z",
+		},
+		{
+			name: "year rollover",
+			expr: "0 0 1 * *",
+			from: "2026-12-15T00:00:00Z",
+			want: "2027-01-01T00:00:00Z",
+		},
+		{
+			name: "skips months without the day",
+			expr: "30 23 31 * *",
+			from: "2026-04-01T00:00:00Z",
+			want: "2026-05-31T23:30:00Z",
+		},
+		{
+			name: "leap day",
+			expr: "0 0 29 2 *",
+			from: "2026-03-01T00:00:00Z",
+			want: "2028-02-29T00:00:00Z",
+		},
+		{
+			name: "february 30th never fires",
+			expr: "0 0 30 2 *",
+			from: "2026-03-22T10:00:00Z",
+			want: "",
+		},
+		{
+			name: "evaluated in UTC",
+			expr: "0 12 * * *",
+			from: "2026-03-22T13:30:00+02:00",
+			want: "2026-03-22T12:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			from, err := time.Parse(time.RFC3339, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.Next(from)
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want zero time", tt.from, got)
+				}
+				return
+			}
+			if got.Format(time.RFC3339) != tt.want {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
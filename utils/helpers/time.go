@@ -2,14 +2,36 @@ package helpers
 
 import "time"
 
+// DateTimeLayout is the layout used for every timestamp persisted by FlowX.
+// Milliseconds are always written out, so that timestamps sort in time order
+// when compared as strings. Runs persisted before need a backfill to sort
+// correctly, see "List Runs" in the README.
+const DateTimeLayout = "2006-01-02T15:04:05.000Z"
+
 func GetCurrentDateTime() string {
 	utcTime := time.Now().UTC()
-	return utcTime.Format("2006-01-02T15:04:05.999Z")
+	return utcTime.Format(DateTimeLayout)
 }
 
 func GetNotEndedTime() string {
 	zeroTime := time.Time{}
-	return zeroTime.Format("2006-01-02T15:04:05.999Z")
+	return zeroTime.Format(DateTimeLayout)
+}
+
+// FormatDateTime converts t to UTC and formats it in the persisted layout.
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(DateTimeLayout)
+}
+
+// ParseDateTime parses a timestamp in the persisted layout. Timestamps
+// persisted before milliseconds were always written out are accepted too.
+func ParseDateTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+// Seconds converts a possibly fractional number of seconds to a duration.
+func Seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
 }
 
 func SecondsSince(start time.Time) int {